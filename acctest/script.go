@@ -0,0 +1,43 @@
+package acctest
+
+import (
+	"time"
+
+	"github.com/Soemii/AccTelemetry"
+)
+
+// DefaultScript drives cars around the track at slightly different paces,
+// advancing the session clock by one second per tick.
+func DefaultScript(cars []AccTelemetry.CarInfo) Script {
+	return func(tick int) (update AccTelemetry.RealTimeUpdate, carUpdates []AccTelemetry.RealTimeCarUpdate) {
+		update = AccTelemetry.RealTimeUpdate{
+			SessionType:    AccTelemetry.SessionTypeRace,
+			Phase:          AccTelemetry.SessionPhaseSession,
+			SessionTime:    time.Duration(tick) * time.Second,
+			SessionEndTime: time.Hour - time.Duration(tick)*time.Second,
+			TimeOfDay:      14 * time.Hour,
+			AmbientTemp:    22,
+			TrackTemp:      28,
+		}
+		update.SessionRemainingTime = update.SessionEndTime
+		update.RemainingTime = update.SessionEndTime
+		for i, car := range cars {
+			// one lap takes 100 + i ticks
+			distance := float64(tick) / float64(100+i)
+			laps := int(distance)
+			carUpdates = append(carUpdates, AccTelemetry.RealTimeCarUpdate{
+				CarIndex:       car.Id,
+				DriverCount:    byte(len(car.Drivers)),
+				Gear:           4,
+				CarLocation:    AccTelemetry.CarLocationTrack,
+				Kmh:            180,
+				Position:       uint16(i + 1),
+				CupPosition:    uint16(i + 1),
+				TrackPosition:  uint16(i + 1),
+				SplinePosition: float32(distance - float64(laps)),
+				Laps:           uint16(laps),
+			})
+		}
+		return
+	}
+}
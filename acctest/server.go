@@ -0,0 +1,274 @@
+// Package acctest provides an in-process fake of the ACC broadcasting server
+// for tests and demos.
+package acctest
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Soemii/AccTelemetry"
+)
+
+const (
+	ErrorMessageProtocolVersion = "Protocol version mismatch"
+	ErrorMessagePassword        = "Password is wrong"
+)
+
+// Script returns the realtime update and the car updates sent to a client on
+// its tick-th update interval.
+type Script func(tick int) (AccTelemetry.RealTimeUpdate, []AccTelemetry.RealTimeCarUpdate)
+
+type Config struct {
+	ConnectionPassword string
+	CommandPassword    string
+	TrackData          AccTelemetry.TrackData
+	Cars               []AccTelemetry.CarInfo
+	// Script defaults to DefaultScript(Cars).
+	Script Script
+}
+
+type Server struct {
+	conn   net.PacketConn
+	config Config
+
+	mu       sync.Mutex
+	clients  map[int32]*client
+	nextId   int32
+	requests []AccTelemetry.Request
+	commands []AccTelemetry.Request
+
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+type client struct {
+	addr     net.Addr
+	interval time.Duration
+	readOnly bool
+	stop     chan struct{}
+}
+
+// NewServer starts a server listening on a random local UDP port.
+func NewServer(config Config) (*Server, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	if config.Script == nil {
+		config.Script = DefaultScript(config.Cars)
+	}
+	s := &Server{
+		conn:    conn,
+		config:  config,
+		clients: make(map[int32]*client),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address clients connect to.
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Close stops the server and waits for its goroutines. Calling it again
+// returns the result of the first call.
+func (s *Server) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.closeErr = s.conn.Close()
+		s.DropClients()
+		s.wg.Wait()
+	})
+	return s.closeErr
+}
+
+// Requests returns every message received so far.
+func (s *Server) Requests() []AccTelemetry.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AccTelemetry.Request(nil), s.requests...)
+}
+
+// Commands returns the focus, HUD page and instant replay requests accepted
+// so far. Like ACC, the server ignores them from read-only or unknown
+// connections.
+func (s *Server) Commands() []AccTelemetry.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AccTelemetry.Request(nil), s.commands...)
+}
+
+// DropClients forgets all registrations and stops streaming, like a restart
+// of the game.
+func (s *Server) DropClients() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, c := range s.clients {
+		close(c.stop)
+		delete(s.clients, id)
+	}
+}
+
+// Send delivers event to every registered client.
+func (s *Server) Send(event AccTelemetry.Event) error {
+	data, err := AccTelemetry.EncodeEvent(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.clients {
+		if _, writeErr := s.conn.WriteTo(data, c.addr); writeErr != nil {
+			err = errors.Join(err, writeErr)
+		}
+	}
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	var buff [AccTelemetry.ReadBufferSize]byte
+	for {
+		n, addr, err := s.conn.ReadFrom(buff[:])
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		request, err := AccTelemetry.DecodeRequest(buff[:n])
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, request)
+		s.mu.Unlock()
+		s.handle(request, addr)
+	}
+}
+
+func (s *Server) handle(request AccTelemetry.Request, addr net.Addr) {
+	switch request.Type {
+	case AccTelemetry.OutboundMessageRegisterCommandApplication:
+		s.register(request.Registration, addr)
+	case AccTelemetry.OutboundMessageUnregisterCommandApplication:
+		s.mu.Lock()
+		if c, ok := s.clients[request.Unregister.ConnectionId]; ok {
+			close(c.stop)
+			delete(s.clients, request.Unregister.ConnectionId)
+		}
+		s.mu.Unlock()
+	case AccTelemetry.OutboundMessageRequestEntryList:
+		s.sendEntryList(request.EntryList.ConnectionId, addr)
+	case AccTelemetry.OutboundMessageRequestTrackData:
+		s.write(addr, func() ([]byte, error) {
+			return AccTelemetry.EncodeTrackData(request.TrackData.ConnectionId, s.config.TrackData)
+		})
+	case AccTelemetry.OutboundMessageChangeHudPage:
+		s.command(request, request.HudPage.ConnectionId)
+	case AccTelemetry.OutboundMessageChangeFocus:
+		s.command(request, request.Focus.ConnectionId)
+	case AccTelemetry.OutboundMessageInstantReplayRequest:
+		s.command(request, request.InstantReplay.ConnectionId)
+	}
+}
+
+// command accepts request if connectionId is registered with the command
+// password.
+func (s *Server) command(request AccTelemetry.Request, connectionId int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.clients[connectionId]; ok && !c.readOnly {
+		s.commands = append(s.commands, request)
+	}
+}
+
+func (s *Server) register(request AccTelemetry.RegistrationRequest, addr net.Addr) {
+	result := AccTelemetry.RegistrationResult{Success: true}
+	switch {
+	case request.ProtocolVersion != AccTelemetry.BroadcastingProtocolVersion:
+		result = AccTelemetry.RegistrationResult{ErrorMessage: ErrorMessageProtocolVersion}
+	case request.ConnectionPassword != s.config.ConnectionPassword:
+		result = AccTelemetry.RegistrationResult{ErrorMessage: ErrorMessagePassword}
+	}
+	var c *client
+	if result.Success {
+		result.ReadOnly = request.CommandPassword != s.config.CommandPassword
+		c = &client{
+			addr:     addr,
+			interval: time.Duration(request.MsRealtimeUpdateInterval) * time.Millisecond,
+			readOnly: result.ReadOnly,
+			stop:     make(chan struct{}),
+		}
+		s.mu.Lock()
+		s.nextId++
+		result.ConnectionId = s.nextId
+		s.clients[result.ConnectionId] = c
+		s.mu.Unlock()
+	}
+	s.write(addr, func() ([]byte, error) {
+		return AccTelemetry.EncodeRegistrationResult(result)
+	})
+	if c != nil {
+		s.wg.Add(1)
+		go s.stream(c)
+	}
+}
+
+func (s *Server) sendEntryList(connectionId int32, addr net.Addr) {
+	entryList := make(AccTelemetry.EntryList, len(s.config.Cars))
+	for i, car := range s.config.Cars {
+		entryList[i] = car.Id
+	}
+	s.write(addr, func() ([]byte, error) {
+		return AccTelemetry.EncodeEntryList(connectionId, entryList)
+	})
+	for _, car := range s.config.Cars {
+		s.write(addr, func() ([]byte, error) {
+			return AccTelemetry.EncodeEntryListCar(car)
+		})
+	}
+}
+
+// stream sends the scripted updates at the interval requested by the client.
+func (s *Server) stream(c *client) {
+	defer s.wg.Done()
+	if c.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for tick := 0; ; tick++ {
+		select {
+		case <-ticker.C:
+		case <-c.stop:
+			return
+		case <-s.done:
+			return
+		}
+		update, carUpdates := s.config.Script(tick)
+		s.write(c.addr, func() ([]byte, error) {
+			return AccTelemetry.EncodeRealtimeUpdate(update)
+		})
+		for _, carUpdate := range carUpdates {
+			s.write(c.addr, func() ([]byte, error) {
+				return AccTelemetry.EncodeRealtimeCarUpdate(carUpdate)
+			})
+		}
+	}
+}
+
+func (s *Server) write(addr net.Addr, encode func() ([]byte, error)) {
+	data, err := encode()
+	if err != nil {
+		return
+	}
+	_, _ = s.conn.WriteTo(data, addr)
+}
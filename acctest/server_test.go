@@ -0,0 +1,318 @@
+package acctest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Soemii/AccTelemetry"
+	"github.com/Soemii/goptional"
+)
+
+const (
+	connectionPassword = "asd"
+	commandPassword    = "cmd"
+)
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := NewServer(Config{
+		ConnectionPassword: connectionPassword,
+		CommandPassword:    commandPassword,
+		TrackData:          AccTelemetry.TrackData{Id: AccTelemetry.TrackIdBrandsHatch, Name: "Brands Hatch", Meters: 3908},
+		Cars: []AccTelemetry.CarInfo{
+			{Id: 1, Model: AccTelemetry.CarModelFerrari, RaceNumber: 71},
+			{Id: 2, Model: AccTelemetry.CarModelMercedes, RaceNumber: 88},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func newClient(t *testing.T, s *Server, connectionPassword string, opts ...AccTelemetry.Option) *AccTelemetry.AccUDPClient {
+	t.Helper()
+	opts = append([]AccTelemetry.Option{
+		AccTelemetry.WithoutChannels(),
+		AccTelemetry.WithUpdateInterval(50 * time.Millisecond),
+		AccTelemetry.WithTimeout(time.Second),
+	}, opts...)
+	c, err := AccTelemetry.NewAccUDPClient(s.Addr(), "test", connectionPassword, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// connect registers c and disconnects it when the test ends.
+func connect(t *testing.T, c *AccTelemetry.AccUDPClient) {
+	t.Helper()
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Disconnect() })
+}
+
+func TestRegistration(t *testing.T) {
+	s := newServer(t)
+	c := newClient(t, s, connectionPassword, AccTelemetry.WithCommandPassword(commandPassword))
+	connect(t, c)
+	if c.IsReadOnly() {
+		t.Error("client is read-only with the right command password")
+	}
+	requests := s.Requests()
+	if len(requests) == 0 || requests[0].Type != AccTelemetry.OutboundMessageRegisterCommandApplication {
+		t.Fatalf("first request = %+v, want a registration", requests)
+	}
+	if got := requests[0].Registration.ConnectionPassword; got != connectionPassword {
+		t.Errorf("ConnectionPassword = %q, want %q", got, connectionPassword)
+	}
+}
+
+func TestWrongPassword(t *testing.T) {
+	s := newServer(t)
+	c := newClient(t, s, "wrong")
+	err := c.Run(context.Background())
+	var registrationErr *AccTelemetry.RegistrationError
+	if !errors.As(err, &registrationErr) {
+		t.Fatalf("Run error = %v, want *RegistrationError", err)
+	}
+	if registrationErr.Message != ErrorMessagePassword {
+		t.Errorf("Message = %q, want %q", registrationErr.Message, ErrorMessagePassword)
+	}
+}
+
+func TestRunCancelledWhileConnecting(t *testing.T) {
+	// a socket that never answers the registration
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	c, err := AccTelemetry.NewAccUDPClient(silent.LocalAddr().String(), "test", connectionPassword,
+		AccTelemetry.WithoutChannels(), AccTelemetry.WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = c.Run(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run with a cancelled context error = %v, want context.Canceled", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err = c.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run returned after %v, want it to stop waiting for the registration", elapsed)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	s := newServer(t)
+	c := newClient(t, s, connectionPassword, AccTelemetry.WithCommandPassword("wrong"))
+	connect(t, c)
+	if !c.IsReadOnly() {
+		t.Fatal("client is not read-only with a wrong command password")
+	}
+	if err := c.RequestHudPage("Basic HUD"); !errors.Is(err, AccTelemetry.ErrReadOnly) {
+		t.Errorf("RequestHudPage error = %v, want ErrReadOnly", err)
+	}
+	if err := c.RequestFocusedCar(goptional.NewOptional[uint16](1), goptional.NewEmptyOptional[string](), goptional.NewEmptyOptional[string]()); !errors.Is(err, AccTelemetry.ErrReadOnly) {
+		t.Errorf("RequestFocusedCar error = %v, want ErrReadOnly", err)
+	}
+	if err := c.RequestTrackData(); err != nil {
+		t.Errorf("RequestTrackData error = %v, want nil", err)
+	}
+}
+
+func TestReconnectAfterDropClients(t *testing.T) {
+	s := newServer(t)
+	c := newClient(t, s, connectionPassword)
+	registrations, err := c.Subscribe(AccTelemetry.EventFilter{
+		Types: []AccTelemetry.InboundMessage{AccTelemetry.InboundMessageRegistrationResult},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connect(t, c)
+	s.DropClients()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-registrations.Events():
+			if event.Registration.Success && event.Registration.ConnectionId > 1 {
+				return
+			}
+		case <-timeout:
+			t.Fatal("client did not re-register after the server dropped it")
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	s := newServer(t)
+	c := newClient(t, s, connectionPassword, AccTelemetry.WithCommandPassword(commandPassword))
+	connect(t, c)
+	if err := c.RequestHudPage("Basic HUD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RequestFocusedCar(goptional.NewOptional[uint16](2), goptional.NewOptional("Drivable"), goptional.NewOptional("Chase")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RequestInstantReplay(60000, 10000, 1, "Helicam", "Helicam"); err != nil {
+		t.Fatal(err)
+	}
+	var commands []AccTelemetry.Request
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if commands = s.Commands(); len(commands) == 3 {
+			break
+		}
+	}
+	if len(commands) != 3 {
+		t.Fatalf("received %d commands, want 3", len(commands))
+	}
+	if commands[0].Type != AccTelemetry.OutboundMessageChangeHudPage || commands[0].HudPage.HudPage != "Basic HUD" {
+		t.Errorf("commands[0] = %+v, want the HUD page request", commands[0])
+	}
+	if commands[1].Type != AccTelemetry.OutboundMessageChangeFocus || commands[1].Focus.CarIndex.OrElse(0) != 2 {
+		t.Errorf("commands[1] = %+v, want the focus request", commands[1])
+	}
+	if commands[2].Type != AccTelemetry.OutboundMessageInstantReplayRequest || commands[2].InstantReplay.InitialCameraSet != "Helicam" {
+		t.Errorf("commands[2] = %+v, want the instant replay request", commands[2])
+	}
+}
+
+func TestCommandsFromReadOnlyConnections(t *testing.T) {
+	s := newServer(t)
+	// the first registration gets connection id 1
+	connect(t, newClient(t, s, connectionPassword, AccTelemetry.WithCommandPassword("wrong")))
+	c := newClient(t, s, connectionPassword, AccTelemetry.WithCommandPassword(commandPassword))
+	connect(t, c)
+	conn, err := net.Dial("udp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	readOnly, err := AccTelemetry.EncodeHudPageRequest(AccTelemetry.HudPageRequest{ConnectionId: 1, HudPage: "Blank"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := AccTelemetry.EncodeFocusRequest(AccTelemetry.FocusRequest{ConnectionId: 99, CarIndex: goptional.NewOptional[uint16](1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{readOnly, unknown} {
+		if _, err = conn.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = c.RequestHudPage("Basic HUD"); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if countCommandRequests(s) == 3 {
+			break
+		}
+	}
+	if received := countCommandRequests(s); received != 3 {
+		t.Fatalf("received %d command requests, want 3", received)
+	}
+	commands := s.Commands()
+	if len(commands) != 1 || commands[0].HudPage.HudPage != "Basic HUD" {
+		t.Errorf("commands = %+v, want only the request of the command connection", commands)
+	}
+}
+
+func countCommandRequests(s *Server) (n int) {
+	for _, request := range s.Requests() {
+		switch request.Type {
+		case AccTelemetry.OutboundMessageChangeFocus, AccTelemetry.OutboundMessageChangeHudPage, AccTelemetry.OutboundMessageInstantReplayRequest:
+			n++
+		}
+	}
+	return
+}
+
+func TestCloseTwice(t *testing.T) {
+	s := newServer(t)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close error = %v, want nil", err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	s := newServer(t)
+	var capture bytes.Buffer
+	recorder, err := AccTelemetry.NewCaptureWriter(&capture, AccTelemetry.CaptureHeader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(t, s, connectionPassword, AccTelemetry.WithCommandPassword(commandPassword), AccTelemetry.WithRecorder(recorder))
+	if err = c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.RequestTrackData(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if err = c.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if err = recorder.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := AccTelemetry.NewCaptureReader(&capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registrations := 0
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Direction != AccTelemetry.CaptureOutbound {
+			continue
+		}
+		request, err := AccTelemetry.DecodeRequest(record.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if request.Type != AccTelemetry.OutboundMessageRegisterCommandApplication {
+			continue
+		}
+		registrations++
+		if request.Registration.ConnectionPassword != "" || request.Registration.CommandPassword != "" {
+			t.Errorf("recorded registration %+v contains passwords", request.Registration)
+		}
+		if request.Registration.DisplayName != "test" {
+			t.Errorf("recorded DisplayName = %q, want %q", request.Registration.DisplayName, "test")
+		}
+	}
+	if registrations != 1 {
+		t.Errorf("recorded %d registrations, want 1", registrations)
+	}
+	header := reader.Header()
+	if header.TrackId != AccTelemetry.TrackIdBrandsHatch || header.TrackName != "Brands Hatch" {
+		t.Errorf("header track = %v %q, want the track of the server", header.TrackId, header.TrackName)
+	}
+	if header.SessionType != AccTelemetry.SessionTypeRace {
+		t.Errorf("header session type = %v, want %v", header.SessionType, AccTelemetry.SessionTypeRace)
+	}
+}
@@ -0,0 +1,217 @@
+package AccTelemetry
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// BackpressurePolicy decides what happens when an event channel is full.
+// Handlers are always called synchronously and are not affected.
+type BackpressurePolicy byte
+
+const (
+	// BackpressureBlock waits until the consumer receives the event. A slow
+	// consumer stalls the decoding of every other message.
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropOldest discards the oldest buffered event to make room.
+	BackpressureDropOldest
+	// BackpressureDropNewest discards the event that does not fit.
+	BackpressureDropNewest
+	// BackpressureCoalesce keeps only the latest undelivered event, per car
+	// for realtime car updates.
+	BackpressureCoalesce
+)
+
+// Stream identifies one of the event channels of AccUDPClient.
+type Stream byte
+
+const (
+	StreamErrors Stream = iota
+	StreamBroadcastEvents
+	StreamTrackData
+	StreamEntryListCars
+	StreamEntryLists
+	StreamRealtimeUpdates
+	StreamRealtimeCarUpdates
+	StreamConnectionStates
+	StreamRegistrations
+	streamCount
+)
+
+type eventStream[T any] struct {
+	handlers handlerList[T]
+	policy   BackpressurePolicy
+	key      func(T) int
+	dropped  *atomic.Uint64
+	// cancel stops the delivery independently of the client, nil for the
+	// streams of the exported channels.
+	cancel  chan struct{}
+	running sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[int]T
+	order   []int
+	wake    chan struct{}
+}
+
+func (s *eventStream[T]) init(dropped *atomic.Uint64, policy BackpressurePolicy, key func(T) int) {
+	s.dropped = dropped
+	s.policy = policy
+	s.key = key
+}
+
+func (s *eventStream[T]) coalesce(event T) (replaced bool) {
+	k := 0
+	if s.key != nil {
+		k = s.key(event)
+	}
+	s.mu.Lock()
+	_, replaced = s.pending[k]
+	if !replaced {
+		s.order = append(s.order, k)
+	}
+	s.pending[k] = event
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return
+}
+
+func (s *eventStream[T]) next() (event T, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.order) == 0 {
+		return
+	}
+	k := s.order[0]
+	s.order = s.order[1:]
+	event, ok = s.pending[k]
+	delete(s.pending, k)
+	return
+}
+
+// pump delivers coalesced events to ch until the client stops.
+func (s *eventStream[T]) pump(c *AccUDPClient, ch chan T) {
+	defer c.pumps.Done()
+	defer s.running.Done()
+	for {
+		select {
+		case <-s.wake:
+		case <-c.stop:
+			return
+		case <-s.cancel:
+			return
+		}
+		for event, ok := s.next(); ok; event, ok = s.next() {
+			select {
+			case ch <- event:
+			case <-c.stop:
+				return
+			case <-s.cancel:
+				return
+			}
+		}
+	}
+}
+
+func startPump[T any](c *AccUDPClient, s *eventStream[T], ch chan T) {
+	if ch == nil || s.policy != BackpressureCoalesce {
+		return
+	}
+	s.pending = make(map[int]T)
+	s.order = nil
+	s.wake = make(chan struct{}, 1)
+	c.pumps.Add(1)
+	s.running.Add(1)
+	go s.pump(c, ch)
+}
+
+func (c *AccUDPClient) startPumps() {
+	startPump(c, &c.errStream, c.ErrChannel)
+	startPump(c, &c.broadcastEventStream, c.BroadCastEventChannel)
+	startPump(c, &c.trackDataStream, c.TrackDataEventChannel)
+	startPump(c, &c.entryListCarStream, c.EntryListCarEventChannel)
+	startPump(c, &c.entryListStream, c.EntryListEventChannel)
+	startPump(c, &c.realtimeUpdateStream, c.RealtimeUpdateEventChannel)
+	startPump(c, &c.realtimeCarUpdateStream, c.RealtimeCarUpdateEventChannel)
+	startPump(c, &c.connectionStateStream, c.ConnectionStateEventChannel)
+	startPump(c, &c.registrationStream, c.RegistrationEventChannel)
+	c.subscribersMu.Lock()
+	defer c.subscribersMu.Unlock()
+	c.subscribersActive = true
+	for sub := range c.subscribers {
+		startPump(c, &sub.stream, sub.ch)
+	}
+}
+
+func (c *AccUDPClient) initStreams() {
+	c.errStream.init(&c.dropped[StreamErrors], c.policies[StreamErrors], nil)
+	c.broadcastEventStream.init(&c.dropped[StreamBroadcastEvents], c.policies[StreamBroadcastEvents], nil)
+	c.trackDataStream.init(&c.dropped[StreamTrackData], c.policies[StreamTrackData], nil)
+	c.entryListCarStream.init(&c.dropped[StreamEntryListCars], c.policies[StreamEntryListCars], func(car CarInfo) int {
+		return int(car.Id)
+	})
+	c.entryListStream.init(&c.dropped[StreamEntryLists], c.policies[StreamEntryLists], nil)
+	c.realtimeUpdateStream.init(&c.dropped[StreamRealtimeUpdates], c.policies[StreamRealtimeUpdates], nil)
+	c.realtimeCarUpdateStream.init(&c.dropped[StreamRealtimeCarUpdates], c.policies[StreamRealtimeCarUpdates], func(update RealTimeCarUpdate) int {
+		return int(update.CarIndex)
+	})
+	c.connectionStateStream.init(&c.dropped[StreamConnectionStates], c.policies[StreamConnectionStates], nil)
+	c.registrationStream.init(&c.dropped[StreamRegistrations], c.policies[StreamRegistrations], nil)
+}
+
+// DroppedEvents returns how many events of stream were discarded by its
+// backpressure policy.
+func (c *AccUDPClient) DroppedEvents(stream Stream) uint64 {
+	if stream >= streamCount {
+		return 0
+	}
+	return c.dropped[stream].Load()
+}
+
+// emit calls the registered handlers and delivers the event to ch according
+// to the backpressure policy of the stream. Nothing is delivered to a nil
+// channel.
+func emit[T any](c *AccUDPClient, s *eventStream[T], ch chan T, event T) {
+	s.handlers.call(event)
+	if ch == nil {
+		return
+	}
+	switch s.policy {
+	case BackpressureDropOldest:
+		for {
+			select {
+			case ch <- event:
+				return
+			default:
+			}
+			select {
+			case <-ch:
+				s.dropped.Add(1)
+			default:
+				if cap(ch) == 0 {
+					s.dropped.Add(1)
+					return
+				}
+			}
+		}
+	case BackpressureDropNewest:
+		select {
+		case ch <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	case BackpressureCoalesce:
+		if s.coalesce(event) {
+			s.dropped.Add(1)
+		}
+	default:
+		select {
+		case ch <- event:
+		case <-c.stop:
+		case <-s.cancel:
+		}
+	}
+}
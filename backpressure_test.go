@@ -0,0 +1,103 @@
+package AccTelemetry
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConnectionStreamsDropOldest(t *testing.T) {
+	c := newReplayClient(t, WithChannelBuffer(1))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// nobody reads the channels, the connection loop must not block
+		for i := 0; i < 3; i++ {
+			c.setState(ConnectionStateRegistered)
+			c.setState(ConnectionStateLost)
+			emit(c, &c.registrationStream, c.RegistrationEventChannel, RegistrationResult{ConnectionId: int32(i)})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("emitting connection events blocked")
+	}
+	if state := <-c.ConnectionStateEventChannel; state != ConnectionStateLost {
+		t.Errorf("buffered state = %v, want %v", state, ConnectionStateLost)
+	}
+	if result := <-c.RegistrationEventChannel; result.ConnectionId != 2 {
+		t.Errorf("buffered registration = %+v, want connection id 2", result)
+	}
+	if dropped := c.DroppedEvents(StreamConnectionStates); dropped != 5 {
+		t.Errorf("dropped %d connection states, want 5", dropped)
+	}
+	if dropped := c.DroppedEvents(StreamRegistrations); dropped != 2 {
+		t.Errorf("dropped %d registrations, want 2", dropped)
+	}
+}
+
+func TestBackpressureDrop(t *testing.T) {
+	tests := []struct {
+		policy BackpressurePolicy
+		want   []int32
+	}{
+		{BackpressureDropOldest, []int32{4, 5}},
+		{BackpressureDropNewest, []int32{1, 2}},
+	}
+	for _, test := range tests {
+		c := newReplayClient(t, WithChannelBuffer(2), WithBackpressure(StreamBroadcastEvents, test.policy))
+		for i := int32(1); i <= 5; i++ {
+			emit(c, &c.broadcastEventStream, c.BroadCastEventChannel, BroadCastEvent{TimeMs: i})
+		}
+		var got []int32
+		for len(c.BroadCastEventChannel) > 0 {
+			got = append(got, (<-c.BroadCastEventChannel).TimeMs)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("policy %d delivered %v, want %v", test.policy, got, test.want)
+		}
+		if dropped := c.DroppedEvents(StreamBroadcastEvents); dropped != 3 {
+			t.Errorf("policy %d dropped %d events, want 3", test.policy, dropped)
+		}
+	}
+}
+
+func TestBackpressureCoalesce(t *testing.T) {
+	// the pump blocks on the unbuffered channel until the updates are read
+	c := newReplayClient(t, WithChannelBuffer(0), WithBackpressure(StreamRealtimeCarUpdates, BackpressureCoalesce))
+	c.start()
+	t.Cleanup(func() {
+		c.stopOnce.Do(func() { close(c.stop) })
+		c.pumps.Wait()
+	})
+	updates := []RealTimeCarUpdate{
+		{CarIndex: 1, Laps: 0},
+		{CarIndex: 2, Laps: 0},
+		{CarIndex: 1, Laps: 1},
+		{CarIndex: 1, Laps: 2},
+		{CarIndex: 2, Laps: 1},
+	}
+	for _, update := range updates {
+		emit(c, &c.realtimeCarUpdateStream, c.RealtimeCarUpdateEventChannel, update)
+	}
+	// every car ends with its latest update, replaced ones count as dropped
+	latest := map[uint16]uint16{}
+	received := 0
+	timeout := time.After(5 * time.Second)
+	for latest[1] != 2 || latest[2] != 1 {
+		select {
+		case update := <-c.RealtimeCarUpdateEventChannel:
+			if update.Laps < latest[update.CarIndex] {
+				t.Fatalf("car %d went back to lap %d", update.CarIndex, update.Laps)
+			}
+			latest[update.CarIndex] = update.Laps
+			received++
+		case <-timeout:
+			t.Fatalf("latest updates were not delivered, got %v", latest)
+		}
+	}
+	if dropped := c.DroppedEvents(StreamRealtimeCarUpdates); received+int(dropped) != len(updates) || dropped == 0 {
+		t.Errorf("received %d and dropped %d of %d updates", received, dropped, len(updates))
+	}
+}
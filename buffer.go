@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"github.com/Soemii/goptional"
-	"log"
+	"io"
+	"math"
+	"slices"
 	"time"
 )
 
@@ -26,9 +29,38 @@ const InvalidSectorTime = (2 << 30) - 1
 
 type EntryList []uint16
 
+// DecodeError describes which field of an inbound message could not be
+// decoded. Offset is the position of the field in the datagram.
+type DecodeError struct {
+	Message InboundMessage
+	Field   string
+	Offset  int
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cannot decode %v.%s at offset %d: %v", e.Message, e.Field, e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrLengthOverflow is returned when a string or list is too long for the
+// count field the protocol encodes its length in.
+var ErrLengthOverflow = errors.New("length does not fit the count field")
+
+// writeCount writes n as the count field T, failing instead of truncating.
+func writeCount[T uint8 | uint16 | int16](b *bytes.Buffer, field string, n int) error {
+	count := T(n)
+	if int(count) != n {
+		return fmt.Errorf("%s: %w: %d", field, ErrLengthOverflow, n)
+	}
+	return writeNumber(b, count)
+}
+
 func writeString(b *bytes.Buffer, data string) error {
-	length := int16(len(data))
-	lengthErr := binary.Write(b, binary.LittleEndian, length)
+	lengthErr := writeCount[int16](b, "string length", len(data))
 	if lengthErr != nil {
 		return lengthErr
 	}
@@ -43,16 +75,31 @@ func writeString(b *bytes.Buffer, data string) error {
 }
 
 func readString(b *bytes.Buffer) (s string, err error) {
-	length, err := readNumber[int16](b)
+	length, err := readField[int16](b, "length")
+	if err != nil {
+		return
+	}
+	offset := bufferOffset(b)
+	if length < 0 {
+		err = &DecodeError{Offset: offset, Err: fmt.Errorf("negative string length %d", length)}
+		return
+	}
 	buff := make([]byte, length)
-	n, err := b.Read(buff)
-	if int16(n) != length {
-		err = errors.New("mis")
+	_, err = io.ReadFull(b, buff)
+	if err != nil {
+		err = &DecodeError{Offset: offset, Err: fmt.Errorf("string of %d bytes: %w", length, err)}
+		return
 	}
 	s = string(buff)
 	return
 }
 
+func readStringField(b *bytes.Buffer, field string) (s string, err error) {
+	s, err = readString(b)
+	err = prefixField(field, err)
+	return
+}
+
 func writeNumber[d ReadBuffer | AccModels](b *bytes.Buffer, data d) error {
 	return binary.Write(b, binary.LittleEndian, data)
 }
@@ -62,18 +109,64 @@ func readNumber[d ReadBuffer | AccModels](b *bytes.Buffer) (data d, err error) {
 	return
 }
 
+// readField reads a number and reports a failure as *DecodeError of field.
+func readField[d ReadBuffer | AccModels](b *bytes.Buffer, field string) (data d, err error) {
+	offset := bufferOffset(b)
+	data, err = readNumber[d](b)
+	if err != nil {
+		err = &DecodeError{Field: field, Offset: offset, Err: err}
+	}
+	return
+}
+
+// bufferOffset returns the number of bytes already read from b.
+func bufferOffset(b *bytes.Buffer) int {
+	return b.Cap() - b.Available() - b.Len()
+}
+
+// prefixField qualifies the field of a nested *DecodeError with field.
+func prefixField(field string, err error) error {
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		if decodeErr.Field == "" {
+			decodeErr.Field = field
+		} else {
+			decodeErr.Field = field + "." + decodeErr.Field
+		}
+	}
+	return err
+}
+
+// setDecodeMessage records the message type in a *DecodeError returned by a
+// decoder.
+func setDecodeMessage(message InboundMessage, err *error) {
+	var decodeErr *DecodeError
+	if errors.As(*err, &decodeErr) && decodeErr.Message == 0 {
+		decodeErr.Message = message
+	}
+}
+
 func divideByTen(b byte, err error) (float32, error) {
 	return float32(b) / 10, err
 }
 
-func parseTime(float322 float32, err error) (time.Time, error) {
-	log.Println(float322)
-	return time.Now(), errors.New("cannot convert float32 to time")
+func multiplyByTen(f float32) byte {
+	return byte(math.Round(float64(f) * 10))
+}
+
+func millisecondsToDuration(ms float32, err error) (time.Duration, error) {
+	return time.Duration(float64(ms) * float64(time.Millisecond)), err
+}
+
+func secondsToDuration(s float32, err error) (time.Duration, error) {
+	return time.Duration(float64(s) * float64(time.Second)), err
 }
 
-func parseDuration(float322 float32, err error) (time.Duration, error) {
-	log.Println(float322)
-	return time.Duration(0), errors.New("cannot convert float32 to duration")
+// durationToMilliseconds converts d to the float32 the protocol sends, which
+// only has 24 bits of precision: past about 4.6 hours of session time the
+// value is no longer exact to the millisecond, and nanoseconds never survive.
+func durationToMilliseconds(d time.Duration) float32 {
+	return float32(float64(d) / float64(time.Millisecond))
 }
 
 func readLap(b *bytes.Buffer) (lap LapInfo, err error) {
@@ -81,42 +174,42 @@ func readLap(b *bytes.Buffer) (lap LapInfo, err error) {
 	var split int32
 	var isOutLap bool
 	var isInLap bool
-	lap.LapTimeMs, err = readNumber[int32](b)
+	lap.LapTimeMs, err = readField[int32](b, "LapTimeMs")
 	if err != nil {
 		return
 	}
-	lap.CarIndex, err = readNumber[uint16](b)
+	lap.CarIndex, err = readField[uint16](b, "CarIndex")
 	if err != nil {
 		return
 	}
-	lap.DriverIndex, err = readNumber[uint16](b)
+	lap.DriverIndex, err = readField[uint16](b, "DriverIndex")
 	if err != nil {
 		return
 	}
-	splitCount, err = readNumber[byte](b)
+	splitCount, err = readField[byte](b, "SplitCount")
 	if err != nil {
 		return
 	}
 	for i := byte(0); i < splitCount; i++ {
-		split, err = readNumber[int32](b)
+		split, err = readField[int32](b, fmt.Sprintf("Splits[%d]", i))
 		if err != nil {
 			return
 		}
 		lap.Splits = append(lap.Splits, split)
 	}
-	lap.IsInvalid, err = readNumber[bool](b)
+	lap.IsInvalid, err = readField[bool](b, "IsInvalid")
 	if err != nil {
 		return
 	}
-	lap.IsValidForBest, err = readNumber[bool](b)
+	lap.IsValidForBest, err = readField[bool](b, "IsValidForBest")
 	if err != nil {
 		return
 	}
-	isOutLap, err = readNumber[bool](b)
+	isOutLap, err = readField[bool](b, "IsOutLap")
 	if err != nil {
 		return
 	}
-	isInLap, err = readNumber[bool](b)
+	isInLap, err = readField[bool](b, "IsInLap")
 	if err != nil {
 		return
 	}
@@ -127,32 +220,35 @@ func readLap(b *bytes.Buffer) (lap LapInfo, err error) {
 	} else {
 		lap.LapType = LapTypeRegular
 	}
-	for len(lap.Splits) < 3 {
+	for len(lap.Splits) < lapSectors {
 		lap.Splits = append(lap.Splits, 0)
 	}
 	return
 }
 
-func readConnectionResponse(b *bytes.Buffer) (connectionId int32, connectionSuccess bool, isReadOnly bool, err error) {
-	connectionId, err = readNumber[int32](b)
+func readLapField(b *bytes.Buffer, field string) (lap LapInfo, err error) {
+	lap, err = readLap(b)
+	err = prefixField(field, err)
+	return
+}
+
+func readConnectionResponse(b *bytes.Buffer) (result RegistrationResult, err error) {
+	result.ConnectionId, err = readNumber[int32](b)
 	if err != nil {
 		return
 	}
-	connectionSuccess, err = readNumber[bool](b)
+	result.Success, err = readNumber[bool](b)
 	if err != nil {
 		return
 	}
-	isReadOnly, err = readNumber[bool](b)
+	result.ReadOnly, err = readNumber[bool](b)
 	if err != nil {
 		return
 	}
-	errMsg, err := readString(b)
+	result.ErrorMessage, err = readString(b)
 	if err != nil {
 		return
 	}
-	if errMsg != "" {
-		err = errors.New(errMsg)
-	}
 	return
 }
 
@@ -165,7 +261,9 @@ func readEntryListResponse(b *bytes.Buffer) (connectionId int32, entryList Entry
 	if err != nil {
 		return
 	}
-	entryList = make(EntryList, entryCount)
+	if entryCount > 0 {
+		entryList = make(EntryList, entryCount)
+	}
 	for i := uint16(0); i < entryCount; i++ {
 		var entry uint16
 		entry, err = readNumber[uint16](b)
@@ -211,7 +309,9 @@ func readEntryListCarResponse(b *bytes.Buffer) (car CarInfo, err error) {
 	if err != nil {
 		return
 	}
-	car.Drivers = make([]DriverInfo, driversCount)
+	if driversCount > 0 {
+		car.Drivers = make([]DriverInfo, driversCount)
+	}
 	for i := uint8(0); i < driversCount; i++ {
 		car.Drivers[i].FirstName, err = readString(b)
 		if err != nil {
@@ -238,28 +338,96 @@ func readEntryListCarResponse(b *bytes.Buffer) (car CarInfo, err error) {
 }
 
 func readRealtimeUpdateResponse(b *bytes.Buffer) (update RealTimeUpdate, err error) {
-	update.EventIndex, err = readNumber[uint16](b)
-	update.SessionIndex, err = readNumber[uint16](b)
-	update.SessionType, err = readNumber[SessionType](b)
-	update.Phase, err = readNumber[SessionPhase](b)
-	update.SessionTime, err = parseTime(readNumber[float32](b))
-	update.SessionEndTime, err = parseTime(readNumber[float32](b))
-	update.FocusedCarIndex, err = readNumber[int32](b)
-	update.ActiveCameraSet, err = readString(b)
-	update.ActiveCamera, err = readString(b)
-	update.CurrentHudPage, err = readString(b)
-	update.IsReplaying, err = readNumber[bool](b)
+	defer setDecodeMessage(InboundMessageRealtimeUpdate, &err)
+	update.EventIndex, err = readField[uint16](b, "EventIndex")
+	if err != nil {
+		return
+	}
+	update.SessionIndex, err = readField[uint16](b, "SessionIndex")
+	if err != nil {
+		return
+	}
+	update.SessionType, err = readField[SessionType](b, "SessionType")
+	if err != nil {
+		return
+	}
+	update.Phase, err = readField[SessionPhase](b, "Phase")
+	if err != nil {
+		return
+	}
+	update.SessionTime, err = millisecondsToDuration(readField[float32](b, "SessionTime"))
+	if err != nil {
+		return
+	}
+	update.SessionEndTime, err = millisecondsToDuration(readField[float32](b, "SessionEndTime"))
+	if err != nil {
+		return
+	}
+	update.FocusedCarIndex, err = readField[int32](b, "FocusedCarIndex")
+	if err != nil {
+		return
+	}
+	update.ActiveCameraSet, err = readStringField(b, "ActiveCameraSet")
+	if err != nil {
+		return
+	}
+	update.ActiveCamera, err = readStringField(b, "ActiveCamera")
+	if err != nil {
+		return
+	}
+	update.CurrentHudPage, err = readStringField(b, "CurrentHudPage")
+	if err != nil {
+		return
+	}
+	update.IsReplaying, err = readField[bool](b, "IsReplaying")
+	if err != nil {
+		return
+	}
 	if update.IsReplaying {
-		update.ReplaySessionTime, err = parseTime(readNumber[float32](b))
-		update.ReplayRemainingTime, err = parseDuration(readNumber[float32](b))
-	}
-	update.TimeOfDay, err = parseTime(readNumber[float32](b))
-	update.AmbientTemp, err = readNumber[byte](b)
-	update.TrackTemp, err = readNumber[byte](b)
-	update.Clouds, err = divideByTen(readNumber[byte](b))
-	update.RainLevel, err = divideByTen(readNumber[byte](b))
-	update.Wetness, err = divideByTen(readNumber[byte](b))
-	update.BestSessionLap, err = readLap(b)
+		update.ReplaySessionTime, err = millisecondsToDuration(readField[float32](b, "ReplaySessionTime"))
+		if err != nil {
+			return
+		}
+		update.ReplayRemainingTime, err = millisecondsToDuration(readField[float32](b, "ReplayRemainingTime"))
+		if err != nil {
+			return
+		}
+	}
+	update.TimeOfDay, err = secondsToDuration(readField[float32](b, "TimeOfDay"))
+	if err != nil {
+		return
+	}
+	update.AmbientTemp, err = readField[byte](b, "AmbientTemp")
+	if err != nil {
+		return
+	}
+	update.TrackTemp, err = readField[byte](b, "TrackTemp")
+	if err != nil {
+		return
+	}
+	update.Clouds, err = divideByTen(readField[byte](b, "Clouds"))
+	if err != nil {
+		return
+	}
+	update.RainLevel, err = divideByTen(readField[byte](b, "RainLevel"))
+	if err != nil {
+		return
+	}
+	update.Wetness, err = divideByTen(readField[byte](b, "Wetness"))
+	if err != nil {
+		return
+	}
+	update.BestSessionLap, err = readLapField(b, "BestSessionLap")
+	if err != nil {
+		return
+	}
+	update.BestLapCarIndex = update.BestSessionLap.CarIndex
+	update.BestLapDriverIndex = update.BestSessionLap.DriverIndex
+	update.SessionRemainingTime = max(update.SessionEndTime, 0)
+	update.RemainingTime = update.SessionRemainingTime
+	if update.IsReplaying {
+		update.RemainingTime = update.ReplayRemainingTime
+	}
 	return
 }
 
@@ -367,7 +535,9 @@ func readTrackDataResponse(b *bytes.Buffer) (connectionId int32, trackData Track
 	if err != nil {
 		return
 	}
-	trackData.CameraSets = make(map[string][]string)
+	if cameraSetCount > 0 {
+		trackData.CameraSets = make(map[string][]string, cameraSetCount)
+	}
 	for i := byte(0); i < cameraSetCount; i++ {
 		camSetName, err = readString(b)
 		if err != nil {
@@ -377,6 +547,7 @@ func readTrackDataResponse(b *bytes.Buffer) (connectionId int32, trackData Track
 		if err != nil {
 			return
 		}
+		trackData.CameraSets[camSetName] = nil
 		for j := byte(0); j < cameraCount; j++ {
 			cameraName, err = readString(b)
 			if err != nil {
@@ -386,6 +557,9 @@ func readTrackDataResponse(b *bytes.Buffer) (connectionId int32, trackData Track
 		}
 	}
 	hudCount, err = readNumber[byte](b)
+	if err != nil {
+		return
+	}
 	for i := byte(0); i < hudCount; i++ {
 		hudName, err = readString(b)
 		if err != nil {
@@ -416,11 +590,11 @@ func readBroadcastingEvent(b *bytes.Buffer) (event BroadCastEvent, err error) {
 	return
 }
 
-func writeRegistration(b *bytes.Buffer, displayName string, connectionPassword string, msRealtimeUpdateInterval int32, commandPassword string) error {
+func writeRegistration(b *bytes.Buffer, protocolVersion byte, displayName string, connectionPassword string, msRealtimeUpdateInterval int32, commandPassword string) error {
 	if err := writeNumber(b, OutboundMessageRegisterCommandApplication); err != nil {
 		return err
 	}
-	if err := writeNumber(b, BroadcastingProtocolVersion); err != nil {
+	if err := writeNumber(b, protocolVersion); err != nil {
 		return err
 	}
 	if err := writeString(b, displayName); err != nil {
@@ -527,7 +701,7 @@ func writeRequestInstantReplay(b *bytes.Buffer, connectionId int32, startSession
 }
 
 func writeRequestHudPage(b *bytes.Buffer, connectionId int32, hudPage string) (err error) {
-	if err = writeNumber(b, OutboundMessageInstantReplayRequest); err != nil {
+	if err = writeNumber(b, OutboundMessageChangeHudPage); err != nil {
 		return
 	}
 	if err = writeNumber(b, connectionId); err != nil {
@@ -538,3 +712,422 @@ func writeRequestHudPage(b *bytes.Buffer, connectionId int32, hudPage string) (e
 	}
 	return
 }
+
+func writeLap(b *bytes.Buffer, lap LapInfo) (err error) {
+	if err = writeNumber(b, lap.LapTimeMs); err != nil {
+		return
+	}
+	if err = writeNumber(b, lap.CarIndex); err != nil {
+		return
+	}
+	if err = writeNumber(b, lap.DriverIndex); err != nil {
+		return
+	}
+	if err = writeCount[byte](b, "Splits", len(lap.Splits)); err != nil {
+		return
+	}
+	for _, split := range lap.Splits {
+		if err = writeNumber(b, split); err != nil {
+			return
+		}
+	}
+	if err = writeNumber(b, lap.IsInvalid); err != nil {
+		return
+	}
+	if err = writeNumber(b, lap.IsValidForBest); err != nil {
+		return
+	}
+	if err = writeNumber(b, lap.LapType == LapTypeOutlap); err != nil {
+		return
+	}
+	if err = writeNumber(b, lap.LapType == LapTypeInlap); err != nil {
+		return
+	}
+	return
+}
+
+func writeConnectionResponse(b *bytes.Buffer, result RegistrationResult) (err error) {
+	if err = writeNumber(b, InboundMessageRegistrationResult); err != nil {
+		return
+	}
+	if err = writeNumber(b, result.ConnectionId); err != nil {
+		return
+	}
+	if err = writeNumber(b, result.Success); err != nil {
+		return
+	}
+	if err = writeNumber(b, result.ReadOnly); err != nil {
+		return
+	}
+	if err = writeString(b, result.ErrorMessage); err != nil {
+		return
+	}
+	return
+}
+
+func writeEntryListResponse(b *bytes.Buffer, connectionId int32, entryList EntryList) (err error) {
+	if err = writeNumber(b, InboundMessageEntryList); err != nil {
+		return
+	}
+	if err = writeNumber(b, connectionId); err != nil {
+		return
+	}
+	if err = writeCount[uint16](b, "EntryList", len(entryList)); err != nil {
+		return
+	}
+	for _, entry := range entryList {
+		if err = writeNumber(b, entry); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func writeEntryListCarResponse(b *bytes.Buffer, car CarInfo) (err error) {
+	if err = writeNumber(b, InboundMessageEntryListCar); err != nil {
+		return
+	}
+	if err = writeNumber(b, car.Id); err != nil {
+		return
+	}
+	if err = writeNumber(b, car.Model); err != nil {
+		return
+	}
+	if err = writeString(b, car.TeamName); err != nil {
+		return
+	}
+	if err = writeNumber(b, car.RaceNumber); err != nil {
+		return
+	}
+	if err = writeNumber(b, car.CupCategory); err != nil {
+		return
+	}
+	if err = writeNumber(b, car.CurrentDriverId); err != nil {
+		return
+	}
+	if err = writeNumber(b, car.Nationality); err != nil {
+		return
+	}
+	if err = writeCount[uint8](b, "Drivers", len(car.Drivers)); err != nil {
+		return
+	}
+	for _, driver := range car.Drivers {
+		if err = writeString(b, driver.FirstName); err != nil {
+			return
+		}
+		if err = writeString(b, driver.LastName); err != nil {
+			return
+		}
+		if err = writeString(b, driver.ShortName); err != nil {
+			return
+		}
+		if err = writeNumber(b, driver.Category); err != nil {
+			return
+		}
+		if err = writeNumber(b, driver.Nationality); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func writeRealtimeUpdateResponse(b *bytes.Buffer, update RealTimeUpdate) (err error) {
+	if err = writeNumber(b, InboundMessageRealtimeUpdate); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.EventIndex); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.SessionIndex); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.SessionType); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Phase); err != nil {
+		return
+	}
+	if err = writeNumber(b, durationToMilliseconds(update.SessionTime)); err != nil {
+		return
+	}
+	if err = writeNumber(b, durationToMilliseconds(update.SessionEndTime)); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.FocusedCarIndex); err != nil {
+		return
+	}
+	if err = writeString(b, update.ActiveCameraSet); err != nil {
+		return
+	}
+	if err = writeString(b, update.ActiveCamera); err != nil {
+		return
+	}
+	if err = writeString(b, update.CurrentHudPage); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.IsReplaying); err != nil {
+		return
+	}
+	if update.IsReplaying {
+		if err = writeNumber(b, durationToMilliseconds(update.ReplaySessionTime)); err != nil {
+			return
+		}
+		if err = writeNumber(b, durationToMilliseconds(update.ReplayRemainingTime)); err != nil {
+			return
+		}
+	}
+	if err = writeNumber(b, float32(update.TimeOfDay.Seconds())); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.AmbientTemp); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.TrackTemp); err != nil {
+		return
+	}
+	if err = writeNumber(b, multiplyByTen(update.Clouds)); err != nil {
+		return
+	}
+	if err = writeNumber(b, multiplyByTen(update.RainLevel)); err != nil {
+		return
+	}
+	if err = writeNumber(b, multiplyByTen(update.Wetness)); err != nil {
+		return
+	}
+	if err = writeLap(b, update.BestSessionLap); err != nil {
+		return
+	}
+	return
+}
+
+func writeRealtimeCarUpdateResponse(b *bytes.Buffer, update RealTimeCarUpdate) (err error) {
+	if err = writeNumber(b, InboundMessageRealtimeCarUpdate); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.CarIndex); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.DriverIndex); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.DriverCount); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Gear+2); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.WorldPosX); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.WorldPosY); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Yaw); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.CarLocation); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Kmh); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Position); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.CupPosition); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.TrackPosition); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.SplinePosition); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Laps); err != nil {
+		return
+	}
+	if err = writeNumber(b, update.Delta); err != nil {
+		return
+	}
+	if err = writeLap(b, update.BestSessionLap); err != nil {
+		return
+	}
+	if err = writeLap(b, update.LastLap); err != nil {
+		return
+	}
+	if err = writeLap(b, update.CurrentLap); err != nil {
+		return
+	}
+	return
+}
+
+func writeTrackDataResponse(b *bytes.Buffer, connectionId int32, trackData TrackData) (err error) {
+	if err = writeNumber(b, InboundMessageTrackData); err != nil {
+		return
+	}
+	if err = writeNumber(b, connectionId); err != nil {
+		return
+	}
+	if err = writeString(b, trackData.Name); err != nil {
+		return
+	}
+	if err = writeNumber(b, trackData.Id); err != nil {
+		return
+	}
+	if err = writeNumber(b, trackData.Meters); err != nil {
+		return
+	}
+	if err = writeCount[byte](b, "CameraSets", len(trackData.CameraSets)); err != nil {
+		return
+	}
+	camSetNames := make([]string, 0, len(trackData.CameraSets))
+	for camSetName := range trackData.CameraSets {
+		camSetNames = append(camSetNames, camSetName)
+	}
+	slices.Sort(camSetNames)
+	for _, camSetName := range camSetNames {
+		cameras := trackData.CameraSets[camSetName]
+		if err = writeString(b, camSetName); err != nil {
+			return
+		}
+		if err = writeCount[byte](b, "CameraSets["+camSetName+"]", len(cameras)); err != nil {
+			return
+		}
+		for _, cameraName := range cameras {
+			if err = writeString(b, cameraName); err != nil {
+				return
+			}
+		}
+	}
+	if err = writeCount[byte](b, "HudPages", len(trackData.HudPages)); err != nil {
+		return
+	}
+	for _, hudName := range trackData.HudPages {
+		if err = writeString(b, hudName); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func writeBroadcastingEvent(b *bytes.Buffer, event BroadCastEvent) (err error) {
+	if err = writeNumber(b, InboundMessageBroadcastingEvent); err != nil {
+		return
+	}
+	if err = writeNumber(b, event.Type); err != nil {
+		return
+	}
+	if err = writeString(b, event.Msg); err != nil {
+		return
+	}
+	if err = writeNumber(b, event.TimeMs); err != nil {
+		return
+	}
+	if err = writeNumber(b, event.CarId); err != nil {
+		return
+	}
+	return
+}
+
+func readRegistration(b *bytes.Buffer) (request RegistrationRequest, err error) {
+	request.ProtocolVersion, err = readNumber[byte](b)
+	if err != nil {
+		return
+	}
+	request.DisplayName, err = readString(b)
+	if err != nil {
+		return
+	}
+	request.ConnectionPassword, err = readString(b)
+	if err != nil {
+		return
+	}
+	request.MsRealtimeUpdateInterval, err = readNumber[int32](b)
+	if err != nil {
+		return
+	}
+	request.CommandPassword, err = readString(b)
+	if err != nil {
+		return
+	}
+	return
+}
+
+func readFocusedCar(b *bytes.Buffer) (request FocusRequest, err error) {
+	request.ConnectionId, err = readNumber[int32](b)
+	if err != nil {
+		return
+	}
+	var hasCar bool
+	hasCar, err = readNumber[bool](b)
+	if err != nil {
+		return
+	}
+	if hasCar {
+		var carIndex uint16
+		carIndex, err = readNumber[uint16](b)
+		if err != nil {
+			return
+		}
+		request.CarIndex = goptional.NewOptional(carIndex)
+	}
+	var hasCamera bool
+	hasCamera, err = readNumber[bool](b)
+	if err != nil {
+		return
+	}
+	if hasCamera {
+		var cameraSet, camera string
+		cameraSet, err = readString(b)
+		if err != nil {
+			return
+		}
+		camera, err = readString(b)
+		if err != nil {
+			return
+		}
+		request.CameraSet = goptional.NewOptional(cameraSet)
+		request.Camera = goptional.NewOptional(camera)
+	}
+	return
+}
+
+func readRequestInstantReplay(b *bytes.Buffer) (request InstantReplayRequest, err error) {
+	request.ConnectionId, err = readNumber[int32](b)
+	if err != nil {
+		return
+	}
+	request.StartSessionTime, err = readNumber[float32](b)
+	if err != nil {
+		return
+	}
+	request.DurationMS, err = readNumber[float32](b)
+	if err != nil {
+		return
+	}
+	request.InitialFocusedCarIndex, err = readNumber[int32](b)
+	if err != nil {
+		return
+	}
+	request.InitialCameraSet, err = readString(b)
+	if err != nil {
+		return
+	}
+	request.InitialCamera, err = readString(b)
+	if err != nil {
+		return
+	}
+	return
+}
+
+func readRequestHudPage(b *bytes.Buffer) (request HudPageRequest, err error) {
+	request.ConnectionId, err = readNumber[int32](b)
+	if err != nil {
+		return
+	}
+	request.HudPage, err = readString(b)
+	if err != nil {
+		return
+	}
+	return
+}
@@ -0,0 +1,387 @@
+package AccTelemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Capture files store raw broadcasting datagrams. All numbers are little
+// endian and strings are encoded like in the broadcasting protocol (int16
+// length followed by the bytes).
+//
+// Header:
+//
+//	magic            [8]byte "ACCCAPT\x00"
+//	format version   uint16
+//	protocol version byte
+//	created          int64, unix nanoseconds
+//	track id         byte
+//	track name       string
+//	session type     byte
+//	session index    uint16
+//
+// The header is followed by records until the end of the file:
+//
+//	offset    int64, nanoseconds since created, monotonic
+//	direction byte, 1 = inbound, 2 = outbound, 3 = metadata
+//	length    uint32
+//	datagram  [length]byte
+//
+// A metadata record replaces the track and session of the header, encoded
+// like in the header, once they become known after the capture was created.
+//
+// Records are only ever appended, so a capture that was cut off ends with a
+// partial record that readers report as io.ErrUnexpectedEOF.
+
+const CaptureFormatVersion uint16 = 1
+
+var captureMagic = [8]byte{'A', 'C', 'C', 'C', 'A', 'P', 'T', 0}
+
+var ErrNotACapture = errors.New("not an ACC capture file")
+
+type CaptureDirection byte
+
+const (
+	CaptureInbound CaptureDirection = iota + 1
+	CaptureOutbound
+	captureMetadata
+)
+
+type CaptureHeader struct {
+	FormatVersion   uint16
+	ProtocolVersion byte
+	Created         time.Time
+	TrackId         TrackId
+	TrackName       string
+	SessionType     SessionType
+	SessionIndex    uint16
+}
+
+type CaptureRecord struct {
+	// Offset is the time since the capture was created.
+	Offset    time.Duration
+	Direction CaptureDirection
+	Data      []byte
+}
+
+// CaptureWriter appends datagrams to a capture. It is safe for concurrent
+// use.
+type CaptureWriter struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	start  time.Time
+	header CaptureHeader
+}
+
+// CreateCapture creates a new capture file at path. Existing files are not
+// overwritten.
+func CreateCapture(path string, header CaptureHeader) (*CaptureWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewCaptureWriter(file, header)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	w.closer = file
+	return w, nil
+}
+
+// NewCaptureWriter writes the header to w. FormatVersion and ProtocolVersion
+// are always set to the versions of this package, Created defaults to now.
+func NewCaptureWriter(w io.Writer, header CaptureHeader) (*CaptureWriter, error) {
+	header.FormatVersion = CaptureFormatVersion
+	header.ProtocolVersion = BroadcastingProtocolVersion
+	start := time.Now()
+	if header.Created.IsZero() {
+		header.Created = start
+	}
+	var buffer bytes.Buffer
+	if err := writeCaptureHeader(&buffer, header); err != nil {
+		return nil, err
+	}
+	cw := &CaptureWriter{w: bufio.NewWriter(w), start: start, header: header}
+	if _, err := cw.w.Write(buffer.Bytes()); err != nil {
+		return nil, err
+	}
+	return cw, cw.w.Flush()
+}
+
+func (w *CaptureWriter) Header() CaptureHeader {
+	return w.header
+}
+
+// Record appends a datagram stamped with the time since the capture started.
+func (w *CaptureWriter) Record(direction CaptureDirection, data []byte) error {
+	return w.WriteRecord(CaptureRecord{Offset: time.Since(w.start), Direction: direction, Data: data})
+}
+
+func (w *CaptureWriter) WriteRecord(record CaptureRecord) (err error) {
+	var buffer bytes.Buffer
+	if err = writeCaptureRecord(&buffer, record); err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(buffer.Bytes())
+	return
+}
+
+// SetTrack records the track of the capture unless the header already names
+// it. The client calls it when track data arrives.
+func (w *CaptureWriter) SetTrack(id TrackId, name string) error {
+	return w.updateHeader(func(header *CaptureHeader) {
+		header.TrackId = id
+		header.TrackName = name
+	})
+}
+
+// SetSession records the session of the capture unless the header already
+// names it. The client calls it with every realtime update.
+func (w *CaptureWriter) SetSession(sessionType SessionType, sessionIndex uint16) error {
+	return w.updateHeader(func(header *CaptureHeader) {
+		header.SessionType = sessionType
+		header.SessionIndex = sessionIndex
+	})
+}
+
+func (w *CaptureWriter) updateHeader(update func(header *CaptureHeader)) (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	header := w.header
+	update(&header)
+	if header == w.header {
+		return
+	}
+	w.header = header
+	var data bytes.Buffer
+	if err = writeCaptureMetadata(&data, header); err != nil {
+		return
+	}
+	var buffer bytes.Buffer
+	err = writeCaptureRecord(&buffer, CaptureRecord{Offset: time.Since(w.start), Direction: captureMetadata, Data: data.Bytes()})
+	if err != nil {
+		return
+	}
+	_, err = w.w.Write(buffer.Bytes())
+	return
+}
+
+func writeCaptureRecord(b *bytes.Buffer, record CaptureRecord) (err error) {
+	if err = writeNumber(b, int64(record.Offset)); err != nil {
+		return
+	}
+	if err = writeNumber(b, byte(record.Direction)); err != nil {
+		return
+	}
+	if err = writeNumber(b, uint32(len(record.Data))); err != nil {
+		return
+	}
+	b.Write(record.Data)
+	return
+}
+
+func (w *CaptureWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Flush()
+}
+
+// Close flushes the capture and closes the file opened by CreateCapture.
+func (w *CaptureWriter) Close() error {
+	err := w.Flush()
+	if w.closer != nil {
+		err = errors.Join(err, w.closer.Close())
+	}
+	return err
+}
+
+type CaptureReader struct {
+	r      *bufio.Reader
+	closer io.Closer
+	header CaptureHeader
+	// pos is the position of the next record in the capture.
+	pos int64
+}
+
+func OpenCapture(path string) (*CaptureReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewCaptureReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	r.closer = file
+	return r, nil
+}
+
+// NewCaptureReader reads the header of a capture from r.
+func NewCaptureReader(r io.Reader) (cr *CaptureReader, err error) {
+	cr = &CaptureReader{r: bufio.NewReader(r)}
+	cr.header, err = readCaptureHeader(cr.r)
+	if err != nil {
+		return nil, err
+	}
+	cr.pos = captureHeaderSize(cr.header)
+	return
+}
+
+// Header returns the header of the capture, with the track and session of
+// the metadata records read so far.
+func (r *CaptureReader) Header() CaptureHeader {
+	return r.header
+}
+
+// Next returns the next inbound or outbound record or io.EOF at the end of
+// the capture. Metadata records are applied to Header.
+func (r *CaptureReader) Next() (record CaptureRecord, err error) {
+	for {
+		record, err = readCaptureRecord(r.r)
+		if err != nil {
+			return
+		}
+		r.pos += captureRecordHeaderSize + int64(len(record.Data))
+		if record.Direction != captureMetadata {
+			return
+		}
+		if err = readCaptureMetadata(bytes.NewReader(record.Data), &r.header); err != nil {
+			return
+		}
+	}
+}
+
+func (r *CaptureReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+const captureRecordHeaderSize = 8 + 1 + 4
+
+func captureHeaderSize(header CaptureHeader) int64 {
+	return int64(len(captureMagic)) + 2 + 1 + 8 + 1 + 2 + int64(len(header.TrackName)) + 1 + 2
+}
+
+func readCaptureRecord(r io.Reader) (record CaptureRecord, err error) {
+	var head struct {
+		Offset    int64
+		Direction CaptureDirection
+		Length    uint32
+	}
+	if err = binary.Read(r, binary.LittleEndian, &head); err != nil {
+		return
+	}
+	record.Offset = time.Duration(head.Offset)
+	record.Direction = head.Direction
+	record.Data = make([]byte, head.Length)
+	if _, err = io.ReadFull(r, record.Data); errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func writeCaptureHeader(b *bytes.Buffer, header CaptureHeader) (err error) {
+	b.Write(captureMagic[:])
+	if err = writeNumber(b, header.FormatVersion); err != nil {
+		return
+	}
+	if err = writeNumber(b, header.ProtocolVersion); err != nil {
+		return
+	}
+	if err = writeNumber(b, header.Created.UnixNano()); err != nil {
+		return
+	}
+	return writeCaptureMetadata(b, header)
+}
+
+func writeCaptureMetadata(b *bytes.Buffer, header CaptureHeader) (err error) {
+	if err = writeNumber(b, header.TrackId); err != nil {
+		return
+	}
+	if err = writeString(b, header.TrackName); err != nil {
+		return
+	}
+	if err = writeNumber(b, header.SessionType); err != nil {
+		return
+	}
+	if err = writeNumber(b, header.SessionIndex); err != nil {
+		return
+	}
+	return
+}
+
+func readCaptureHeader(r io.Reader) (header CaptureHeader, err error) {
+	var fixed struct {
+		Magic           [8]byte
+		FormatVersion   uint16
+		ProtocolVersion byte
+		Created         int64
+	}
+	if err = binary.Read(r, binary.LittleEndian, &fixed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = ErrNotACapture
+		}
+		return
+	}
+	if fixed.Magic != captureMagic {
+		err = ErrNotACapture
+		return
+	}
+	if fixed.FormatVersion != CaptureFormatVersion {
+		err = fmt.Errorf("unsupported capture format version %d", fixed.FormatVersion)
+		return
+	}
+	header = CaptureHeader{
+		FormatVersion:   fixed.FormatVersion,
+		ProtocolVersion: fixed.ProtocolVersion,
+		Created:         time.Unix(0, fixed.Created),
+	}
+	if err = readCaptureMetadata(r, &header); errors.Is(err, io.EOF) {
+		err = ErrNotACapture
+	}
+	return
+}
+
+// readCaptureMetadata reads the track and session into header.
+func readCaptureMetadata(r io.Reader, header *CaptureHeader) (err error) {
+	var track struct {
+		TrackId         TrackId
+		TrackNameLength int16
+	}
+	if err = binary.Read(r, binary.LittleEndian, &track); err != nil {
+		return
+	}
+	if track.TrackNameLength < 0 {
+		return ErrNotACapture
+	}
+	trackName := make([]byte, track.TrackNameLength)
+	if _, err = io.ReadFull(r, trackName); err != nil {
+		return
+	}
+	var session struct {
+		SessionType  SessionType
+		SessionIndex uint16
+	}
+	if err = binary.Read(r, binary.LittleEndian, &session); err != nil {
+		return
+	}
+	header.TrackId = track.TrackId
+	header.TrackName = string(trackName)
+	header.SessionType = session.SessionType
+	header.SessionIndex = session.SessionIndex
+	return
+}
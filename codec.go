@@ -0,0 +1,304 @@
+package AccTelemetry
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Request wraps one outbound message. Type decides which of the other fields
+// is set.
+type Request struct {
+	Type          OutboundMessage
+	Registration  RegistrationRequest
+	Unregister    UnregisterRequest
+	EntryList     EntryListRequest
+	TrackData     TrackDataRequest
+	HudPage       HudPageRequest
+	Focus         FocusRequest
+	InstantReplay InstantReplayRequest
+}
+
+func encode(write func(b *bytes.Buffer) error) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := write(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decodeInbound[T any](data []byte, message InboundMessage, read func(b *bytes.Buffer) (T, error)) (v T, err error) {
+	buffer := bytes.NewBuffer(data)
+	var inboundMessage InboundMessage
+	inboundMessage, err = readNumber[InboundMessage](buffer)
+	if err != nil {
+		return
+	}
+	if inboundMessage != message {
+		err = fmt.Errorf("expected %v message, got %v", message, inboundMessage)
+		return
+	}
+	v, err = read(buffer)
+	setDecodeMessage(message, &err)
+	return
+}
+
+func decodeOutbound[T any](data []byte, message OutboundMessage, read func(b *bytes.Buffer) (T, error)) (v T, err error) {
+	buffer := bytes.NewBuffer(data)
+	var outboundMessage OutboundMessage
+	outboundMessage, err = readNumber[OutboundMessage](buffer)
+	if err != nil {
+		return
+	}
+	if outboundMessage != message {
+		err = fmt.Errorf("expected outbound message %d, got %d", message, outboundMessage)
+		return
+	}
+	return read(buffer)
+}
+
+func readConnectionId(b *bytes.Buffer) (int32, error) {
+	return readNumber[int32](b)
+}
+
+func EncodeRegistrationResult(result RegistrationResult) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeConnectionResponse(b, result) })
+}
+
+func DecodeRegistrationResult(data []byte) (RegistrationResult, error) {
+	return decodeInbound(data, InboundMessageRegistrationResult, readConnectionResponse)
+}
+
+// EncodeRealtimeUpdate encodes update. The durations are sent as float32
+// milliseconds (TimeOfDay as seconds), so they only round-trip to about the
+// millisecond: a SessionTime of 3h0m0.123456789s decodes as 3h0m0.123s.
+func EncodeRealtimeUpdate(update RealTimeUpdate) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeRealtimeUpdateResponse(b, update) })
+}
+
+func DecodeRealtimeUpdate(data []byte) (RealTimeUpdate, error) {
+	return decodeInbound(data, InboundMessageRealtimeUpdate, readRealtimeUpdateResponse)
+}
+
+func EncodeRealtimeCarUpdate(update RealTimeCarUpdate) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeRealtimeCarUpdateResponse(b, update) })
+}
+
+func DecodeRealtimeCarUpdate(data []byte) (RealTimeCarUpdate, error) {
+	return decodeInbound(data, InboundMessageRealtimeCarUpdate, readRealtimeCarUpdateResponse)
+}
+
+func EncodeEntryList(connectionId int32, entryList EntryList) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeEntryListResponse(b, connectionId, entryList) })
+}
+
+func DecodeEntryList(data []byte) (connectionId int32, entryList EntryList, err error) {
+	entryList, err = decodeInbound(data, InboundMessageEntryList, func(b *bytes.Buffer) (l EntryList, err error) {
+		connectionId, l, err = readEntryListResponse(b)
+		return
+	})
+	return
+}
+
+func EncodeTrackData(connectionId int32, trackData TrackData) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeTrackDataResponse(b, connectionId, trackData) })
+}
+
+func DecodeTrackData(data []byte) (connectionId int32, trackData TrackData, err error) {
+	trackData, err = decodeInbound(data, InboundMessageTrackData, func(b *bytes.Buffer) (t TrackData, err error) {
+		connectionId, t, err = readTrackDataResponse(b)
+		return
+	})
+	return
+}
+
+func EncodeEntryListCar(car CarInfo) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeEntryListCarResponse(b, car) })
+}
+
+func DecodeEntryListCar(data []byte) (CarInfo, error) {
+	return decodeInbound(data, InboundMessageEntryListCar, readEntryListCarResponse)
+}
+
+func EncodeBroadcastEvent(event BroadCastEvent) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeBroadcastingEvent(b, event) })
+}
+
+func DecodeBroadcastEvent(data []byte) (BroadCastEvent, error) {
+	return decodeInbound(data, InboundMessageBroadcastingEvent, readBroadcastingEvent)
+}
+
+// EncodeEvent encodes the inbound message wrapped by event. It fails with
+// ErrLengthOverflow when a string or list does not fit its count field.
+func EncodeEvent(event Event) ([]byte, error) {
+	switch event.Type {
+	case InboundMessageRegistrationResult:
+		return EncodeRegistrationResult(event.Registration)
+	case InboundMessageRealtimeUpdate:
+		return EncodeRealtimeUpdate(event.RealtimeUpdate)
+	case InboundMessageRealtimeCarUpdate:
+		return EncodeRealtimeCarUpdate(event.RealtimeCarUpdate)
+	case InboundMessageEntryList:
+		return EncodeEntryList(event.ConnectionId, event.EntryList)
+	case InboundMessageTrackData:
+		return EncodeTrackData(event.ConnectionId, event.TrackData)
+	case InboundMessageEntryListCar:
+		return EncodeEntryListCar(event.EntryListCar)
+	case InboundMessageBroadcastingEvent:
+		return EncodeBroadcastEvent(event.BroadcastEvent)
+	}
+	return nil, fmt.Errorf("unknown inbound message %d", event.Type)
+}
+
+// DecodeEvent decodes any inbound message. Empty lists and maps decode as
+// nil, except for the splits of laps, which are padded to three sectors. A
+// decoded lap with fewer splits on the wire therefore encodes to different
+// bytes.
+func DecodeEvent(data []byte) (event Event, err error) {
+	buffer := bytes.NewBuffer(data)
+	event.Type, err = readNumber[InboundMessage](buffer)
+	if err != nil {
+		return
+	}
+	switch event.Type {
+	case InboundMessageRegistrationResult:
+		event.Registration, err = readConnectionResponse(buffer)
+		event.ConnectionId = event.Registration.ConnectionId
+	case InboundMessageRealtimeUpdate:
+		event.RealtimeUpdate, err = readRealtimeUpdateResponse(buffer)
+	case InboundMessageRealtimeCarUpdate:
+		event.RealtimeCarUpdate, err = readRealtimeCarUpdateResponse(buffer)
+	case InboundMessageEntryList:
+		event.ConnectionId, event.EntryList, err = readEntryListResponse(buffer)
+	case InboundMessageTrackData:
+		event.ConnectionId, event.TrackData, err = readTrackDataResponse(buffer)
+	case InboundMessageEntryListCar:
+		event.EntryListCar, err = readEntryListCarResponse(buffer)
+	case InboundMessageBroadcastingEvent:
+		event.BroadcastEvent, err = readBroadcastingEvent(buffer)
+	default:
+		err = fmt.Errorf("unknown inbound message %d", event.Type)
+	}
+	setDecodeMessage(event.Type, &err)
+	return
+}
+
+func EncodeRegistrationRequest(request RegistrationRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error {
+		return writeRegistration(b, request.ProtocolVersion, request.DisplayName, request.ConnectionPassword, request.MsRealtimeUpdateInterval, request.CommandPassword)
+	})
+}
+
+func DecodeRegistrationRequest(data []byte) (RegistrationRequest, error) {
+	return decodeOutbound(data, OutboundMessageRegisterCommandApplication, readRegistration)
+}
+
+func EncodeUnregisterRequest(request UnregisterRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeDisconnect(b, request.ConnectionId) })
+}
+
+func DecodeUnregisterRequest(data []byte) (UnregisterRequest, error) {
+	return decodeOutbound(data, OutboundMessageUnregisterCommandApplication, func(b *bytes.Buffer) (r UnregisterRequest, err error) {
+		r.ConnectionId, err = readConnectionId(b)
+		return
+	})
+}
+
+func EncodeEntryListRequest(request EntryListRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeEntryListRequest(b, request.ConnectionId) })
+}
+
+func DecodeEntryListRequest(data []byte) (EntryListRequest, error) {
+	return decodeOutbound(data, OutboundMessageRequestEntryList, func(b *bytes.Buffer) (r EntryListRequest, err error) {
+		r.ConnectionId, err = readConnectionId(b)
+		return
+	})
+}
+
+func EncodeTrackDataRequest(request TrackDataRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeTrackDataRequest(b, request.ConnectionId) })
+}
+
+func DecodeTrackDataRequest(data []byte) (TrackDataRequest, error) {
+	return decodeOutbound(data, OutboundMessageRequestTrackData, func(b *bytes.Buffer) (r TrackDataRequest, err error) {
+		r.ConnectionId, err = readConnectionId(b)
+		return
+	})
+}
+
+func EncodeHudPageRequest(request HudPageRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error { return writeRequestHudPage(b, request.ConnectionId, request.HudPage) })
+}
+
+func DecodeHudPageRequest(data []byte) (HudPageRequest, error) {
+	return decodeOutbound(data, OutboundMessageChangeHudPage, readRequestHudPage)
+}
+
+func EncodeFocusRequest(request FocusRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error {
+		return writeFocusedCar(b, request.ConnectionId, request.CarIndex, request.CameraSet, request.Camera)
+	})
+}
+
+func DecodeFocusRequest(data []byte) (FocusRequest, error) {
+	return decodeOutbound(data, OutboundMessageChangeFocus, readFocusedCar)
+}
+
+func EncodeInstantReplayRequest(request InstantReplayRequest) ([]byte, error) {
+	return encode(func(b *bytes.Buffer) error {
+		return writeRequestInstantReplay(b, request.ConnectionId, request.StartSessionTime, request.DurationMS, request.InitialFocusedCarIndex, request.InitialCameraSet, request.InitialCamera)
+	})
+}
+
+func DecodeInstantReplayRequest(data []byte) (InstantReplayRequest, error) {
+	return decodeOutbound(data, OutboundMessageInstantReplayRequest, readRequestInstantReplay)
+}
+
+// EncodeRequest encodes the outbound message wrapped by request. The replay
+// highlight messages are not implemented by ACC and cannot be encoded.
+func EncodeRequest(request Request) ([]byte, error) {
+	switch request.Type {
+	case OutboundMessageRegisterCommandApplication:
+		return EncodeRegistrationRequest(request.Registration)
+	case OutboundMessageUnregisterCommandApplication:
+		return EncodeUnregisterRequest(request.Unregister)
+	case OutboundMessageRequestEntryList:
+		return EncodeEntryListRequest(request.EntryList)
+	case OutboundMessageRequestTrackData:
+		return EncodeTrackDataRequest(request.TrackData)
+	case OutboundMessageChangeHudPage:
+		return EncodeHudPageRequest(request.HudPage)
+	case OutboundMessageChangeFocus:
+		return EncodeFocusRequest(request.Focus)
+	case OutboundMessageInstantReplayRequest:
+		return EncodeInstantReplayRequest(request.InstantReplay)
+	}
+	return nil, fmt.Errorf("unsupported outbound message %d", request.Type)
+}
+
+// DecodeRequest decodes any outbound message.
+func DecodeRequest(data []byte) (request Request, err error) {
+	buffer := bytes.NewBuffer(data)
+	request.Type, err = readNumber[OutboundMessage](buffer)
+	if err != nil {
+		return
+	}
+	switch request.Type {
+	case OutboundMessageRegisterCommandApplication:
+		request.Registration, err = readRegistration(buffer)
+	case OutboundMessageUnregisterCommandApplication:
+		request.Unregister.ConnectionId, err = readConnectionId(buffer)
+	case OutboundMessageRequestEntryList:
+		request.EntryList.ConnectionId, err = readConnectionId(buffer)
+	case OutboundMessageRequestTrackData:
+		request.TrackData.ConnectionId, err = readConnectionId(buffer)
+	case OutboundMessageChangeHudPage:
+		request.HudPage, err = readRequestHudPage(buffer)
+	case OutboundMessageChangeFocus:
+		request.Focus, err = readFocusedCar(buffer)
+	case OutboundMessageInstantReplayRequest:
+		request.InstantReplay, err = readRequestInstantReplay(buffer)
+	default:
+		err = fmt.Errorf("unsupported outbound message %d", request.Type)
+	}
+	return
+}
@@ -0,0 +1,343 @@
+package AccTelemetry
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Soemii/goptional"
+)
+
+func testLap(carIndex uint16, lapTimeMs int32, lapType LapType) LapInfo {
+	return LapInfo{
+		LapTimeMs:      lapTimeMs,
+		Splits:         []int32{30000, 31000, lapTimeMs - 61000},
+		CarIndex:       carIndex,
+		DriverIndex:    1,
+		IsValidForBest: true,
+		LapType:        lapType,
+	}
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	bestLap := testLap(7, 104123, LapTypeRegular)
+	tests := []struct {
+		name  string
+		event Event
+	}{
+		{"RegistrationResult", Event{
+			Type:         InboundMessageRegistrationResult,
+			ConnectionId: 12,
+			Registration: RegistrationResult{ConnectionId: 12, Success: true, ReadOnly: true, ErrorMessage: "read only"},
+		}},
+		{"RealtimeUpdate", Event{
+			Type: InboundMessageRealtimeUpdate,
+			RealtimeUpdate: RealTimeUpdate{
+				EventIndex:           2,
+				SessionIndex:         1,
+				Phase:                SessionPhaseSession,
+				SessionType:          SessionTypeRace,
+				SessionTime:          20 * time.Minute,
+				SessionEndTime:       40 * time.Minute,
+				SessionRemainingTime: 40 * time.Minute,
+				RemainingTime:        40 * time.Minute,
+				TimeOfDay:            14*time.Hour + 30*time.Minute,
+				FocusedCarIndex:      7,
+				ActiveCameraSet:      "Drivable",
+				ActiveCamera:         "Chase",
+				CurrentHudPage:       "Basic HUD",
+				AmbientTemp:          22,
+				TrackTemp:            31,
+				Clouds:               0.5,
+				RainLevel:            0,
+				Wetness:              0.5,
+				BestSessionLap:       bestLap,
+				BestLapCarIndex:      bestLap.CarIndex,
+				BestLapDriverIndex:   bestLap.DriverIndex,
+			},
+		}},
+		{"RealtimeUpdate replaying", Event{
+			Type: InboundMessageRealtimeUpdate,
+			RealtimeUpdate: RealTimeUpdate{
+				Phase:               SessionPhaseSessionOver,
+				SessionType:         SessionTypeQualifying,
+				SessionTime:         time.Hour,
+				SessionEndTime:      -5 * time.Second,
+				IsReplaying:         true,
+				ReplaySessionTime:   30 * time.Minute,
+				ReplayRemainingTime: 15 * time.Second,
+				RemainingTime:       15 * time.Second,
+				BestSessionLap:      testLap(0, InvalidSectorTime, LapTypeOutlap),
+				BestLapDriverIndex:  1,
+			},
+		}},
+		{"RealtimeCarUpdate", Event{
+			Type: InboundMessageRealtimeCarUpdate,
+			RealtimeCarUpdate: RealTimeCarUpdate{
+				CarIndex:       7,
+				DriverIndex:    1,
+				DriverCount:    2,
+				Gear:           4,
+				WorldPosX:      -120.5,
+				WorldPosY:      88.25,
+				Yaw:            1.5,
+				CarLocation:    CarLocationTrack,
+				Kmh:            212,
+				Position:       3,
+				CupPosition:    2,
+				TrackPosition:  4,
+				SplinePosition: 0.625,
+				Laps:           11,
+				Delta:          -350,
+				BestSessionLap: bestLap,
+				LastLap:        testLap(7, 105000, LapTypeRegular),
+				CurrentLap:     testLap(7, 40000, LapTypeInlap),
+			},
+		}},
+		{"EntryList", Event{
+			Type:         InboundMessageEntryList,
+			ConnectionId: 12,
+			EntryList:    EntryList{7, 3, 1001},
+		}},
+		{"EntryList empty", Event{
+			Type:         InboundMessageEntryList,
+			ConnectionId: 12,
+		}},
+		{"TrackData", Event{
+			Type:         InboundMessageTrackData,
+			ConnectionId: 12,
+			TrackData: TrackData{
+				Id:     TrackIdBrandsHatch,
+				Name:   "Brands Hatch",
+				Meters: 3908,
+				CameraSets: map[string][]string{
+					"Drivable": {"Chase", "FarChase", "Cockpit"},
+					"Helicam":  {"Helicam"},
+					"Empty":    nil,
+				},
+				HudPages: []string{"Blank", "Basic HUD"},
+			},
+		}},
+		{"TrackData empty", Event{
+			Type:         InboundMessageTrackData,
+			ConnectionId: 12,
+			TrackData:    TrackData{Id: TrackIdBrandsHatch, Name: "Brands Hatch"},
+		}},
+		{"EntryListCar", Event{
+			Type: InboundMessageEntryListCar,
+			EntryListCar: CarInfo{
+				Id:              7,
+				Model:           CarModelFerrari,
+				TeamName:        "Team Rosso",
+				RaceNumber:      71,
+				CupCategory:     CupCategoryProAm,
+				CurrentDriverId: 1,
+				Nationality:     NationalityItaly,
+				Drivers: []DriverInfo{
+					{FirstName: "Anna", LastName: "Rossi", ShortName: "ROS", Category: DriverCategoryGold, Nationality: NationalityItaly},
+					{FirstName: "Ben", LastName: "Weber", ShortName: "WEB", Category: DriverCategorySilver, Nationality: NationalityGermany},
+				},
+			},
+		}},
+		{"EntryListCar without drivers", Event{
+			Type:         InboundMessageEntryListCar,
+			EntryListCar: CarInfo{Id: 8, Model: CarModelMercedes, CurrentDriverId: -1},
+		}},
+		{"BroadcastEvent", Event{
+			Type:           InboundMessageBroadcastingEvent,
+			BroadcastEvent: BroadCastEvent{Type: EventTypeLapCompleted, Msg: "Lap completed", TimeMs: 1234567, CarId: 7},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := EncodeEvent(test.event)
+			if err != nil {
+				t.Fatalf("EncodeEvent: %v", err)
+			}
+			decoded, err := DecodeEvent(data)
+			if err != nil {
+				t.Fatalf("DecodeEvent: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, test.event) {
+				t.Errorf("round trip mismatch\n got: %+v\nwant: %+v", decoded, test.event)
+			}
+		})
+	}
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		request Request
+	}{
+		{"Registration", Request{
+			Type: OutboundMessageRegisterCommandApplication,
+			Registration: RegistrationRequest{
+				ProtocolVersion:          4,
+				DisplayName:              "telemetry",
+				ConnectionPassword:       "asd",
+				MsRealtimeUpdateInterval: 250,
+				CommandPassword:          "cmd",
+			},
+		}},
+		{"Unregister", Request{
+			Type:       OutboundMessageUnregisterCommandApplication,
+			Unregister: UnregisterRequest{ConnectionId: 12},
+		}},
+		{"EntryList", Request{
+			Type:      OutboundMessageRequestEntryList,
+			EntryList: EntryListRequest{ConnectionId: 12},
+		}},
+		{"TrackData", Request{
+			Type:      OutboundMessageRequestTrackData,
+			TrackData: TrackDataRequest{ConnectionId: 12},
+		}},
+		{"HudPage", Request{
+			Type:    OutboundMessageChangeHudPage,
+			HudPage: HudPageRequest{ConnectionId: 12, HudPage: "Basic HUD"},
+		}},
+		{"Focus", Request{
+			Type: OutboundMessageChangeFocus,
+			Focus: FocusRequest{
+				ConnectionId: 12,
+				CarIndex:     goptional.NewOptional[uint16](7),
+				CameraSet:    goptional.NewOptional("Drivable"),
+				Camera:       goptional.NewOptional("Chase"),
+			},
+		}},
+		{"Focus car only", Request{
+			Type:  OutboundMessageChangeFocus,
+			Focus: FocusRequest{ConnectionId: 12, CarIndex: goptional.NewOptional[uint16](3)},
+		}},
+		{"InstantReplay", Request{
+			Type: OutboundMessageInstantReplayRequest,
+			InstantReplay: InstantReplayRequest{
+				ConnectionId:           12,
+				StartSessionTime:       600000,
+				DurationMS:             10000,
+				InitialFocusedCarIndex: 7,
+				InitialCameraSet:       "Helicam",
+				InitialCamera:          "Helicam",
+			},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := EncodeRequest(test.request)
+			if err != nil {
+				t.Fatalf("EncodeRequest: %v", err)
+			}
+			decoded, err := DecodeRequest(data)
+			if err != nil {
+				t.Fatalf("DecodeRequest: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, test.request) {
+				t.Errorf("round trip mismatch\n got: %+v\nwant: %+v", decoded, test.request)
+			}
+		})
+	}
+}
+
+func TestSessionTimePrecision(t *testing.T) {
+	data, err := EncodeRealtimeUpdate(RealTimeUpdate{SessionTime: 3*time.Hour + 123456789*time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update, err := DecodeRealtimeUpdate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3*time.Hour + 123*time.Millisecond; update.SessionTime != want {
+		t.Errorf("SessionTime = %v, want %v", update.SessionTime, want)
+	}
+}
+
+func TestLapSplitsPadding(t *testing.T) {
+	tests := []struct {
+		name   string
+		splits []int32
+		want   []int32
+	}{
+		{"no splits", nil, []int32{0, 0, 0}},
+		{"two splits", []int32{30000, 31000}, []int32{30000, 31000, 0}},
+		{"three splits", []int32{30000, 31000, 32000}, []int32{30000, 31000, 32000}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lap := LapInfo{LapTimeMs: InvalidSectorTime, Splits: test.splits, LapType: LapTypeRegular}
+			data, err := EncodeRealtimeCarUpdate(RealTimeCarUpdate{CarIndex: 3, LastLap: lap})
+			if err != nil {
+				t.Fatal(err)
+			}
+			update, err := DecodeRealtimeCarUpdate(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(update.LastLap.Splits, test.want) {
+				t.Errorf("Splits = %v, want %v", update.LastLap.Splits, test.want)
+			}
+			// the padded lap is stable from then on
+			reencoded, err := EncodeRealtimeCarUpdate(update)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := DecodeRealtimeCarUpdate(reencoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, update) {
+				t.Errorf("second round trip = %+v, want %+v", again, update)
+			}
+		})
+	}
+}
+
+func TestEncodeLengthOverflow(t *testing.T) {
+	manyStrings := func(n int) []string {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = "x"
+		}
+		return s
+	}
+	lap := testLap(1, 100000, LapTypeRegular)
+	lap.Splits = make([]int32, 256)
+	tests := []struct {
+		name  string
+		event Event
+	}{
+		{"string", Event{
+			Type:           InboundMessageBroadcastingEvent,
+			BroadcastEvent: BroadCastEvent{Msg: strings.Repeat("x", 1<<15)},
+		}},
+		{"splits", Event{
+			Type:              InboundMessageRealtimeCarUpdate,
+			RealtimeCarUpdate: RealTimeCarUpdate{LastLap: lap},
+		}},
+		{"entry list", Event{
+			Type:      InboundMessageEntryList,
+			EntryList: make(EntryList, 1<<16),
+		}},
+		{"drivers", Event{
+			Type:         InboundMessageEntryListCar,
+			EntryListCar: CarInfo{Drivers: make([]DriverInfo, 256)},
+		}},
+		{"cameras", Event{
+			Type:      InboundMessageTrackData,
+			TrackData: TrackData{CameraSets: map[string][]string{"Drivable": manyStrings(256)}},
+		}},
+		{"hud pages", Event{
+			Type:      InboundMessageTrackData,
+			TrackData: TrackData{HudPages: manyStrings(256)},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := EncodeEvent(test.event); !errors.Is(err, ErrLengthOverflow) {
+				t.Errorf("EncodeEvent error = %v, want ErrLengthOverflow", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package AccTelemetry
+
+import (
+	"bytes"
+	"time"
+)
+
+type ConnectionState byte
+
+const (
+	ConnectionStateConnecting ConnectionState = iota
+	ConnectionStateRegistered
+	ConnectionStateReadOnly
+	ConnectionStateLost
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionStateConnecting:
+		return "connecting"
+	case ConnectionStateRegistered:
+		return "registered"
+	case ConnectionStateReadOnly:
+		return "read-only"
+	case ConnectionStateLost:
+		return "lost"
+	}
+	return "unknown"
+}
+
+const (
+	MinReconnectDelay = time.Second
+	MaxReconnectDelay = 30 * time.Second
+
+	// missedUpdatesUntilLost is the number of realtime update intervals
+	// without any update after which the connection is considered lost.
+	missedUpdatesUntilLost = 5
+	minStaleDuration       = 2 * time.Second
+)
+
+func (c *AccUDPClient) register() (err error) {
+	var buffer bytes.Buffer
+	err = writeRegistration(&buffer, BroadcastingProtocolVersion, c.displayName, c.connectionPassword, c.msRealtimeUpdateInterval, c.commandPassword)
+	if err != nil {
+		return
+	}
+	err = c.sendBuffer(buffer)
+	return
+}
+
+// staleDuration is the time without realtime updates after which the
+// connection is considered lost.
+func (c *AccUDPClient) staleDuration() time.Duration {
+	d := time.Duration(c.msRealtimeUpdateInterval) * time.Millisecond * missedUpdatesUntilLost
+	return max(d, minStaleDuration)
+}
+
+// readDeadline bounds every read so the watchdog runs even if ACC is silent.
+func (c *AccUDPClient) readDeadline() time.Time {
+	return time.Now().Add(min(c.timeOutDuration, c.staleDuration()))
+}
+
+func (c *AccUDPClient) setState(state ConnectionState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	emit(c, &c.connectionStateStream, c.ConnectionStateEventChannel, state)
+}
+
+// resetConnectionState prepares the watchdog for a freshly sent registration.
+func (c *AccUDPClient) resetConnectionState() {
+	now := time.Now()
+	c.state = ConnectionStateConnecting
+	c.lastUpdate = now
+	c.retryDelay = MinReconnectDelay
+	c.nextRetry = now.Add(c.retryDelay)
+	c.reconnecting = false
+}
+
+// registered updates the watchdog after a successful (re-)registration.
+func (c *AccUDPClient) registered() {
+	c.lastUpdate = time.Now()
+	c.retryDelay = MinReconnectDelay
+	if !c.reconnecting {
+		return
+	}
+	c.reconnecting = false
+	c.lastEntryListRequest = time.Now()
+	if err := c.RequestEntryList(); err != nil {
+		emit(c, &c.errStream, c.ErrChannel, err)
+	}
+	if err := c.RequestTrackData(); err != nil {
+		emit(c, &c.errStream, c.ErrChannel, err)
+	}
+}
+
+// watchdog detects missing realtime updates and re-registers with exponential
+// backoff until ACC answers again.
+func (c *AccUDPClient) watchdog() {
+	now := time.Now()
+	switch c.state {
+	case ConnectionStateRegistered, ConnectionStateReadOnly:
+		if now.Sub(c.lastUpdate) < c.staleDuration() {
+			return
+		}
+		c.logger.Warn("connection lost", "connectionId", c.connectionId.Load(), "lastUpdate", c.lastUpdate)
+		c.setState(ConnectionStateLost)
+		c.retryDelay = MinReconnectDelay
+		c.nextRetry = now
+	}
+	if now.Before(c.nextRetry) {
+		return
+	}
+	c.reconnecting = true
+	c.setState(ConnectionStateConnecting)
+	c.logger.Info("re-registering", "address", c.address, "retryDelay", c.retryDelay)
+	if err := c.register(); err != nil {
+		emit(c, &c.errStream, c.ErrChannel, err)
+	}
+	c.nextRetry = now.Add(c.retryDelay)
+	c.retryDelay = min(c.retryDelay*2, MaxReconnectDelay)
+}
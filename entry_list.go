@@ -0,0 +1,60 @@
+package AccTelemetry
+
+import "time"
+
+// DefaultEntryListRefresh is the minimum time between two entry list requests
+// sent because of unknown cars.
+const DefaultEntryListRefresh = time.Second
+
+// pendingDriverCount marks a car of the entry list whose entry list car did
+// not arrive yet.
+const pendingDriverCount = -1
+
+func (c *AccUDPClient) knowEntryList(entryList EntryList) {
+	c.entryListReceived = time.Now()
+	c.knownCars = make(map[uint16]int, len(entryList))
+	for _, id := range entryList {
+		c.knownCars[id] = pendingDriverCount
+	}
+}
+
+func (c *AccUDPClient) knowCar(car CarInfo) {
+	if c.knownCars == nil {
+		c.knownCars = make(map[uint16]int)
+	}
+	c.knownCars[car.Id] = len(car.Drivers)
+}
+
+// checkCar requests the entry list if update belongs to a car the client has
+// never seen or if its drivers changed, as recommended by the broadcasting
+// SDK. A car whose entry list car is still missing after the refresh interval
+// counts as unknown, the datagram was probably lost.
+func (c *AccUDPClient) checkCar(update RealTimeCarUpdate) {
+	// a replayed session cannot be asked for its entry list
+	if c.entryListRefresh == 0 || c.conn == nil {
+		return
+	}
+	driverCount, ok := c.knownCars[update.CarIndex]
+	switch {
+	case !ok:
+		c.refreshEntryList("unknown car", update.CarIndex)
+	case driverCount == pendingDriverCount:
+		if time.Since(c.entryListReceived) >= c.entryListRefresh {
+			c.refreshEntryList("entry list car missing", update.CarIndex)
+		}
+	case driverCount != int(update.DriverCount):
+		c.refreshEntryList("driver count changed", update.CarIndex)
+	}
+}
+
+func (c *AccUDPClient) refreshEntryList(reason string, carIndex uint16) {
+	now := time.Now()
+	if now.Sub(c.lastEntryListRequest) < c.entryListRefresh {
+		return
+	}
+	c.lastEntryListRequest = now
+	c.logger.Debug("requesting entry list", "connectionId", c.connectionId.Load(), "reason", reason, "carIndex", carIndex)
+	if err := c.RequestEntryList(); err != nil {
+		emit(c, &c.errStream, c.ErrChannel, err)
+	}
+}
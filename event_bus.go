@@ -0,0 +1,154 @@
+package AccTelemetry
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Event wraps one inbound message. Type decides which of the other fields is
+// set.
+type Event struct {
+	Type InboundMessage
+	// ConnectionId is set for registration results, entry lists and track
+	// data.
+	ConnectionId      int32
+	Registration      RegistrationResult
+	RealtimeUpdate    RealTimeUpdate
+	RealtimeCarUpdate RealTimeCarUpdate
+	EntryList         EntryList
+	TrackData         TrackData
+	EntryListCar      CarInfo
+	BroadcastEvent    BroadCastEvent
+}
+
+// CarIndex returns the index of the car the event refers to, if any.
+func (e Event) CarIndex() (carIndex uint16, ok bool) {
+	switch e.Type {
+	case InboundMessageRealtimeCarUpdate:
+		return e.RealtimeCarUpdate.CarIndex, true
+	case InboundMessageEntryListCar:
+		return e.EntryListCar.Id, true
+	case InboundMessageBroadcastingEvent:
+		if e.BroadcastEvent.CarId >= 0 {
+			return uint16(e.BroadcastEvent.CarId), true
+		}
+	}
+	return 0, false
+}
+
+// EventFilter selects the events of a subscription. Empty Types match every
+// message type. CarIndices only restrict events that refer to a car.
+type EventFilter struct {
+	Types      []InboundMessage
+	CarIndices []uint16
+	// Buffer is the channel buffer of the subscription, DefaultChannelBuffer
+	// if zero.
+	Buffer int
+	Policy BackpressurePolicy
+}
+
+func (f EventFilter) matches(event Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if carIndex, ok := event.CarIndex(); ok && len(f.CarIndices) > 0 {
+		return slices.Contains(f.CarIndices, carIndex)
+	}
+	return true
+}
+
+// Subscription is a subscriber of the events of a client.
+type Subscription struct {
+	client  *AccUDPClient
+	filter  EventFilter
+	stream  eventStream[Event]
+	ch      chan Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Events returns the channel receiving the matching events. It is closed by
+// Cancel and when Run returns.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns the number of events dropped by the backpressure policy of
+// the subscription.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Cancel removes the subscription and closes its channel.
+func (s *Subscription) Cancel() {
+	s.client.unsubscribe(s)
+}
+
+func eventKey(event Event) int {
+	carIndex, _ := event.CarIndex()
+	return int(event.Type)<<16 | int(carIndex)
+}
+
+// Subscribe subscribes to every event matching filter. Each subscriber has its
+// own buffer and backpressure policy.
+func (c *AccUDPClient) Subscribe(filter EventFilter) (*Subscription, error) {
+	if filter.Policy > BackpressureCoalesce {
+		return nil, errors.New("unknown backpressure policy")
+	}
+	if filter.Buffer <= 0 {
+		filter.Buffer = DefaultChannelBuffer
+	}
+	sub := &Subscription{client: c, filter: filter}
+	sub.ch = make(chan Event, filter.Buffer)
+	sub.stream.init(&sub.dropped, filter.Policy, eventKey)
+	sub.stream.cancel = make(chan struct{})
+
+	c.subscribersMu.Lock()
+	if c.subscribers == nil {
+		c.subscribers = make(map[*Subscription]struct{})
+	}
+	c.subscribers[sub] = struct{}{}
+	if c.subscribersActive {
+		startPump(c, &sub.stream, sub.ch)
+	}
+	c.subscribersMu.Unlock()
+	return sub, nil
+}
+
+func (c *AccUDPClient) unsubscribe(sub *Subscription) {
+	sub.once.Do(func() {
+		// cancel first, publish may be blocked on this subscriber while
+		// holding the read lock
+		close(sub.stream.cancel)
+		c.subscribersMu.Lock()
+		delete(c.subscribers, sub)
+		c.subscribersMu.Unlock()
+		sub.stream.running.Wait()
+		close(sub.ch)
+	})
+}
+
+func (c *AccUDPClient) closeSubscriptions() {
+	c.subscribersMu.RLock()
+	subs := make([]*Subscription, 0, len(c.subscribers))
+	for sub := range c.subscribers {
+		subs = append(subs, sub)
+	}
+	c.subscribersMu.RUnlock()
+	for _, sub := range subs {
+		c.unsubscribe(sub)
+	}
+}
+
+// publish delivers event to every matching subscriber.
+func (c *AccUDPClient) publish(event Event) {
+	c.subscribersMu.RLock()
+	defer c.subscribersMu.RUnlock()
+	for sub := range c.subscribers {
+		if sub.filter.matches(event) {
+			emit(c, &sub.stream, sub.ch, event)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package AccTelemetry
+
+import "time"
+
+// rawGraphics mirrors SPageFileGraphic. wchar_t arrays of odd length are
+// followed by 2 bytes of padding.
+type rawGraphics struct {
+	PacketId                 int32
+	Status                   GraphicsStatus
+	Session                  int32
+	CurrentTime              [15]uint16
+	LastTime                 [15]uint16
+	BestTime                 [15]uint16
+	Split                    [15]uint16
+	CompletedLaps            int32
+	Position                 int32
+	ICurrentTime             int32
+	ILastTime                int32
+	IBestTime                int32
+	SessionTimeLeft          float32
+	DistanceTraveled         float32
+	IsInPit                  int32
+	CurrentSectorIndex       int32
+	LastSectorTime           int32
+	NumberOfLaps             int32
+	TyreCompound             [33]uint16
+	_                        [2]byte
+	ReplayTimeMultiplier     float32
+	NormalizedCarPosition    float32
+	ActiveCars               int32
+	CarCoordinates           [60][3]float32
+	CarId                    [60]int32
+	PlayerCarId              int32
+	PenaltyTime              float32
+	Flag                     FlagType
+	Penalty                  int32
+	IdealLineOn              int32
+	IsInPitLane              int32
+	SurfaceGrip              float32
+	MandatoryPitDone         int32
+	WindSpeed                float32
+	WindDirection            float32
+	IsSetupMenuVisible       int32
+	MainDisplayIndex         int32
+	SecondaryDisplayIndex    int32
+	TC                       int32
+	TCCut                    int32
+	EngineMap                int32
+	ABS                      int32
+	FuelXLap                 float32
+	RainLights               int32
+	FlashingLights           int32
+	LightsStage              int32
+	ExhaustTemperature       float32
+	WiperLV                  int32
+	DriverStintTotalTimeLeft int32
+	DriverStintTimeLeft      int32
+	RainTyres                int32
+	SessionIndex             int32
+	UsedFuel                 float32
+	DeltaLapTime             [15]uint16
+	_                        [2]byte
+	IDeltaLapTime            int32
+	EstimatedLapTime         [15]uint16
+	_                        [2]byte
+	IEstimatedLapTime        int32
+	IsDeltaPositive          int32
+	ISplit                   int32
+	IsValidLap               int32
+	FuelEstimatedLaps        float32
+	TrackStatus              [33]uint16
+	_                        [2]byte
+	MissingMandatoryPits     int32
+	Clock                    float32
+	DirectionLightsLeft      int32
+	DirectionLightsRight     int32
+	GlobalYellow             int32
+	GlobalYellow1            int32
+	GlobalYellow2            int32
+	GlobalYellow3            int32
+	GlobalWhite              int32
+	GlobalGreen              int32
+	GlobalChequered          int32
+	GlobalRed                int32
+	MfdTyreSet               int32
+	MfdFuelToAdd             float32
+	MfdTyrePressure          [4]float32
+	TrackGripStatus          int32
+	RainIntensity            int32
+	RainIntensityIn10Min     int32
+	RainIntensityIn30Min     int32
+	CurrentTyreSet           int32
+	StrategyTyreSet          int32
+	GapAhead                 int32
+	GapBehind                int32
+}
+
+// GraphicsPageSize is the size of the graphics page in bytes.
+const GraphicsPageSize = 1588
+
+type GraphicsStatus int32
+
+const (
+	GraphicsStatusOff GraphicsStatus = iota
+	GraphicsStatusReplay
+	GraphicsStatusLive
+	GraphicsStatusPause
+)
+
+type FlagType int32
+
+const (
+	FlagTypeNone FlagType = iota
+	FlagTypeBlue
+	FlagTypeYellow
+	FlagTypeBlack
+	FlagTypeWhite
+	FlagTypeCheckered
+	FlagTypePenalty
+	FlagTypeGreen
+	FlagTypeOrange
+)
+
+// GraphicsCar is the position of a car in world coordinates.
+type GraphicsCar struct {
+	CarId       int32
+	Coordinates Vector3
+}
+
+// Graphics is the graphics page of the player's car. Lap times are given as
+// shown in the HUD and as durations.
+type Graphics struct {
+	PacketId     int32
+	Status       GraphicsStatus
+	SessionType  SessionType
+	SessionIndex int32
+
+	CurrentLapTimeText string
+	LastLapTimeText    string
+	BestLapTimeText    string
+	SplitText          string
+	CurrentLapTime     time.Duration
+	LastLapTime        time.Duration
+	BestLapTime        time.Duration
+	Split              time.Duration
+	DeltaLapTime       time.Duration
+	EstimatedLapTime   time.Duration
+	IsValidLap         bool
+	SessionTimeLeft    time.Duration
+	// Clock is the in-game time of day as duration since midnight.
+	Clock time.Duration
+
+	Position              int32
+	CompletedLaps         int32
+	NumberOfLaps          int32
+	NormalizedCarPosition float32
+	DistanceTraveled      float32
+	CurrentSectorIndex    int32
+	LastSectorTime        time.Duration
+
+	IsInPit              bool
+	IsInPitLane          bool
+	MandatoryPitDone     bool
+	MissingMandatoryPits int32
+
+	Flag        FlagType
+	Penalty     int32
+	PenaltyTime float32
+
+	TC                int32
+	TCCut             int32
+	EngineMap         int32
+	ABS               int32
+	FuelPerLap        float32
+	UsedFuel          float32
+	FuelEstimatedLaps float32
+	TyreCompound      string
+	RainTyres         bool
+	WiperLevel        int32
+	RainLights        bool
+	TrackStatus       string
+	SurfaceGrip       float32
+	WindSpeed         float32
+	WindDirection     float32
+	GapAhead          time.Duration
+	GapBehind         time.Duration
+
+	PlayerCarId int32
+	// Cars holds the coordinates of every active car.
+	Cars []GraphicsCar
+}
+
+// ReadGraphics reads the graphics page from segment.
+func ReadGraphics(segment Segment) (graphics Graphics, err error) {
+	raw, err := readPage[rawGraphics](segment, "graphics")
+	if err != nil {
+		return
+	}
+	return raw.graphics(), nil
+}
+
+// NewGraphicsPoller polls the graphics page every interval and passes on
+// every new frame.
+func NewGraphicsPoller(segment Segment, interval time.Duration) *Poller[Graphics] {
+	return newPoller(interval, func() (Graphics, error) {
+		return ReadGraphics(segment)
+	}, func(frame Graphics) int32 {
+		return frame.PacketId
+	})
+}
+
+func (r *rawGraphics) graphics() Graphics {
+	graphics := Graphics{
+		PacketId:              r.PacketId,
+		Status:                r.Status,
+		SessionType:           sharedMemorySessionType(r.Session),
+		SessionIndex:          r.SessionIndex,
+		CurrentLapTimeText:    wideString(r.CurrentTime[:]),
+		LastLapTimeText:       wideString(r.LastTime[:]),
+		BestLapTimeText:       wideString(r.BestTime[:]),
+		SplitText:             wideString(r.Split[:]),
+		CurrentLapTime:        milliseconds(r.ICurrentTime),
+		LastLapTime:           milliseconds(r.ILastTime),
+		BestLapTime:           milliseconds(r.IBestTime),
+		Split:                 milliseconds(r.ISplit),
+		DeltaLapTime:          milliseconds(r.IDeltaLapTime),
+		EstimatedLapTime:      milliseconds(r.IEstimatedLapTime),
+		IsValidLap:            r.IsValidLap != 0,
+		SessionTimeLeft:       milliseconds(r.SessionTimeLeft),
+		Clock:                 time.Duration(float64(r.Clock) * float64(time.Second)),
+		Position:              r.Position,
+		CompletedLaps:         r.CompletedLaps,
+		NumberOfLaps:          r.NumberOfLaps,
+		NormalizedCarPosition: r.NormalizedCarPosition,
+		DistanceTraveled:      r.DistanceTraveled,
+		CurrentSectorIndex:    r.CurrentSectorIndex,
+		LastSectorTime:        milliseconds(r.LastSectorTime),
+		IsInPit:               r.IsInPit != 0,
+		IsInPitLane:           r.IsInPitLane != 0,
+		MandatoryPitDone:      r.MandatoryPitDone != 0,
+		MissingMandatoryPits:  r.MissingMandatoryPits,
+		Flag:                  r.Flag,
+		Penalty:               r.Penalty,
+		PenaltyTime:           r.PenaltyTime,
+		TC:                    r.TC,
+		TCCut:                 r.TCCut,
+		EngineMap:             r.EngineMap,
+		ABS:                   r.ABS,
+		FuelPerLap:            r.FuelXLap,
+		UsedFuel:              r.UsedFuel,
+		FuelEstimatedLaps:     r.FuelEstimatedLaps,
+		TyreCompound:          wideString(r.TyreCompound[:]),
+		RainTyres:             r.RainTyres != 0,
+		WiperLevel:            r.WiperLV,
+		RainLights:            r.RainLights != 0,
+		TrackStatus:           wideString(r.TrackStatus[:]),
+		SurfaceGrip:           r.SurfaceGrip,
+		WindSpeed:             r.WindSpeed,
+		WindDirection:         r.WindDirection,
+		GapAhead:              milliseconds(r.GapAhead),
+		GapBehind:             milliseconds(r.GapBehind),
+		PlayerCarId:           r.PlayerCarId,
+	}
+	activeCars := min(max(int(r.ActiveCars), 0), len(r.CarId))
+	graphics.Cars = make([]GraphicsCar, activeCars)
+	for i := range graphics.Cars {
+		graphics.Cars[i] = GraphicsCar{CarId: r.CarId[i], Coordinates: vector3(r.CarCoordinates[i])}
+	}
+	return graphics
+}
+
+// sharedMemorySessionType maps ACC_SESSION_TYPE onto SessionType.
+func sharedMemorySessionType(session int32) SessionType {
+	switch session {
+	case 0:
+		return SessionTypePractice
+	case 1:
+		return SessionTypeQualifying
+	case 2:
+		return SessionTypeRace
+	case 3:
+		return SessionTypeHotlap
+	case 7:
+		return SessionTypeHotstint
+	case 8:
+		return SessionTypeHotlapSuperpole
+	default:
+		return SessionTypeUnknown
+	}
+}
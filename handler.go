@@ -0,0 +1,120 @@
+package AccTelemetry
+
+import (
+	"slices"
+	"sync"
+)
+
+// Handler receives the events of an AccUDPClient. Handlers are called on the
+// listener goroutine and must not block.
+type Handler interface {
+	OnRealtimeUpdate(update RealTimeUpdate)
+	OnCarUpdate(update RealTimeCarUpdate)
+	OnEntryListCar(car CarInfo)
+	OnTrackData(trackData TrackData)
+	OnBroadcastEvent(event BroadCastEvent)
+	OnError(err error)
+}
+
+// NopHandler ignores every event. Embed it to implement only the methods of
+// interest.
+type NopHandler struct{}
+
+func (NopHandler) OnRealtimeUpdate(RealTimeUpdate) {}
+func (NopHandler) OnCarUpdate(RealTimeCarUpdate)   {}
+func (NopHandler) OnEntryListCar(CarInfo)          {}
+func (NopHandler) OnTrackData(TrackData)           {}
+func (NopHandler) OnBroadcastEvent(BroadCastEvent) {}
+func (NopHandler) OnError(error)                   {}
+
+// handlerList calls its funcs in the order they were added. The funcs are
+// called without holding the lock, so they may add or remove handlers.
+type handlerList[T any] struct {
+	mu     sync.RWMutex
+	nextId int
+	funcs  []handlerFunc[T]
+}
+
+type handlerFunc[T any] struct {
+	id int
+	fn func(T)
+}
+
+func (l *handlerList[T]) add(fn func(T)) (remove func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.nextId
+	l.nextId++
+	// copy on write, call may still iterate the old slice
+	l.funcs = append(slices.Clip(l.funcs), handlerFunc[T]{id: id, fn: fn})
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.funcs = slices.DeleteFunc(slices.Clone(l.funcs), func(f handlerFunc[T]) bool {
+			return f.id == id
+		})
+	}
+}
+
+func (l *handlerList[T]) call(event T) {
+	l.mu.RLock()
+	funcs := l.funcs
+	l.mu.RUnlock()
+	for _, f := range funcs {
+		f.fn(event)
+	}
+}
+
+// AddHandler subscribes every method of h. The returned function removes it
+// again.
+func (c *AccUDPClient) AddHandler(h Handler) (remove func()) {
+	removers := []func(){
+		c.OnRealtimeUpdate(h.OnRealtimeUpdate),
+		c.OnCarUpdate(h.OnCarUpdate),
+		c.OnEntryListCar(h.OnEntryListCar),
+		c.OnTrackData(h.OnTrackData),
+		c.OnBroadcastEvent(h.OnBroadcastEvent),
+		c.OnError(h.OnError),
+	}
+	return func() {
+		for _, r := range removers {
+			r()
+		}
+	}
+}
+
+func (c *AccUDPClient) OnRealtimeUpdate(fn func(RealTimeUpdate)) (remove func()) {
+	return c.realtimeUpdateStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnCarUpdate(fn func(RealTimeCarUpdate)) (remove func()) {
+	return c.realtimeCarUpdateStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnEntryListCar(fn func(CarInfo)) (remove func()) {
+	return c.entryListCarStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnEntryList(fn func(EntryList)) (remove func()) {
+	return c.entryListStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnTrackData(fn func(TrackData)) (remove func()) {
+	return c.trackDataStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnBroadcastEvent(fn func(BroadCastEvent)) (remove func()) {
+	return c.broadcastEventStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnError(fn func(error)) (remove func()) {
+	return c.errStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnConnectionState(fn func(ConnectionState)) (remove func()) {
+	return c.connectionStateStream.handlers.add(fn)
+}
+
+func (c *AccUDPClient) OnRegistration(fn func(RegistrationResult)) (remove func()) {
+	return c.registrationStream.handlers.add(fn)
+}
@@ -0,0 +1,190 @@
+package AccTelemetry
+
+import (
+	"slices"
+	"time"
+)
+
+// lapPendingUpdates is the number of car updates to wait for ACC to replace
+// LastLap by a timed lap after a lap was completed. If it never does, LastLap
+// is archived as it is.
+const lapPendingUpdates = 3
+
+// ArchivedLap is a completed lap of a car.
+type ArchivedLap struct {
+	LapInfo
+	// Number is the number of the lap in the session, counting from 1.
+	Number int
+	// Driver is the driver of the lap, if the entry list car is known.
+	Driver DriverInfo
+	// Stint counts the stints of the car from 1. A stint starts when the car
+	// leaves the pits or the driver changes on track.
+	Stint int
+	// CompletedAt is the session time at which the lap was archived.
+	CompletedAt time.Duration
+}
+
+// Complete reports whether the lap and all of its sectors were timed.
+func (l ArchivedLap) Complete() bool {
+	if _, ok := l.LapTime(); !ok || len(l.Splits) == 0 {
+		return false
+	}
+	for i := range l.Splits {
+		if _, ok := l.Sector(i); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lapTracker detects completed laps of a car.
+type lapTracker struct {
+	// completed is the number of laps known to be completed, archived the
+	// number of the last archived lap.
+	completed int
+	archived  int
+	// eventLap is the number of the lap the last LapCompleted event was
+	// accounted to.
+	eventLap int
+	// staleLap is LastLap before the pending lap was completed.
+	staleLap LapInfo
+	waited   int
+
+	stint    int
+	driver   uint16
+	location CarLocation
+
+	// sectors maps a lap number to the number of its sectors emitted.
+	sectors map[int]int
+}
+
+func sameLap(a, b LapInfo) bool {
+	return a.LapTimeMs == b.LapTimeMs && a.DriverIndex == b.DriverIndex && slices.Equal(a.Splits, b.Splits)
+}
+
+// tracker returns the lap tracker of car id. s.mu must be held.
+func (s *Session) tracker(id uint16) *lapTracker {
+	tracker, ok := s.trackers[id]
+	if !ok {
+		tracker = &lapTracker{}
+		s.trackers[id] = tracker
+	}
+	return tracker
+}
+
+// lapCompleted marks the current lap of car as completed. s.mu must be held.
+func (s *Session) lapCompleted(car *CarState, tracker *lapTracker, laps int) {
+	if laps <= tracker.completed {
+		return
+	}
+	if tracker.completed == tracker.archived {
+		tracker.staleLap = cloneLap(car.Update.LastLap)
+		tracker.waited = 0
+	}
+	tracker.completed = laps
+}
+
+// trackLaps updates the stint of car and archives its last lap once ACC
+// reports it. s.mu must be held.
+func (s *Session) trackLaps(car *CarState, update RealTimeCarUpdate) (archived *ArchivedLap) {
+	tracker := s.tracker(update.CarIndex)
+	if !car.HasUpdate {
+		// joined mid session, earlier laps are unknown
+		tracker.completed = int(update.Laps)
+		tracker.archived = int(update.Laps)
+		tracker.eventLap = int(update.Laps)
+		if update.CarLocation == CarLocationTrack {
+			tracker.stint = 1
+		}
+		tracker.driver = update.DriverIndex
+		tracker.location = update.CarLocation
+	} else {
+		switch {
+		case tracker.location != CarLocationTrack && update.CarLocation == CarLocationTrack:
+			tracker.stint++
+		case update.CarLocation == CarLocationTrack && tracker.driver != update.DriverIndex:
+			tracker.stint++
+		}
+		tracker.driver = update.DriverIndex
+		tracker.location = update.CarLocation
+		s.lapCompleted(car, tracker, int(update.Laps))
+	}
+	car.Stint = tracker.stint
+	if tracker.completed == tracker.archived {
+		return nil
+	}
+	_, timed := update.LastLap.LapTime()
+	if (!timed || sameLap(update.LastLap, tracker.staleLap)) && tracker.waited < lapPendingUpdates {
+		tracker.waited++
+		return nil
+	}
+	lap := ArchivedLap{
+		LapInfo:     cloneLap(update.LastLap),
+		Number:      tracker.completed,
+		Stint:       car.Stint,
+		CompletedAt: s.update.SessionTime,
+	}
+	if car.HasInfo && int(lap.DriverIndex) < len(car.Info.Drivers) {
+		lap.Driver = car.Info.Drivers[lap.DriverIndex]
+	}
+	car.Laps = append(car.Laps, lap)
+	tracker.archived = tracker.completed
+	return &lap
+}
+
+func (s *Session) handleBroadcastEvent(event BroadCastEvent) {
+	if event.Type != EventTypeLapCompleted {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	car, ok := s.cars[uint16(event.CarId)]
+	if !ok || !car.HasUpdate {
+		return
+	}
+	tracker := s.tracker(uint16(event.CarId))
+	laps := int(car.Update.Laps)
+	if tracker.eventLap < laps {
+		// the car update already counted the lap this event belongs to
+		tracker.eventLap = laps
+		return
+	}
+	// the event usually arrives before the car update counting the lap
+	tracker.eventLap = laps + 1
+	s.lapCompleted(car, tracker, laps+1)
+}
+
+// Laps returns the archived laps of a car.
+func (s *Session) Laps(carIndex uint16) []ArchivedLap {
+	return s.filterLaps(carIndex, func(ArchivedLap) bool { return true })
+}
+
+// DriverLaps returns the archived laps driven by a driver of a car.
+func (s *Session) DriverLaps(carIndex uint16, driverIndex uint16) []ArchivedLap {
+	return s.filterLaps(carIndex, func(lap ArchivedLap) bool { return lap.DriverIndex == driverIndex })
+}
+
+// StintLaps returns the archived laps of a stint of a car.
+func (s *Session) StintLaps(carIndex uint16, stint int) []ArchivedLap {
+	return s.filterLaps(carIndex, func(lap ArchivedLap) bool { return lap.Stint == stint })
+}
+
+func (s *Session) filterLaps(carIndex uint16, keep func(ArchivedLap) bool) (laps []ArchivedLap) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	car, ok := s.cars[carIndex]
+	if !ok {
+		return
+	}
+	for _, lap := range car.Laps {
+		if keep(lap) {
+			laps = append(laps, cloneArchivedLap(lap))
+		}
+	}
+	return
+}
+
+func cloneArchivedLap(lap ArchivedLap) ArchivedLap {
+	lap.LapInfo = cloneLap(lap.LapInfo)
+	return lap
+}
@@ -0,0 +1,132 @@
+package AccTelemetry
+
+import "testing"
+
+// lapStep is a car update of car 1 or, if event is set, a LapCompleted event
+// for it.
+type lapStep struct {
+	event    bool
+	laps     uint16
+	location CarLocation
+	driver   uint16
+	last     LapInfo
+}
+
+func onTrack(laps uint16, last LapInfo) lapStep {
+	return lapStep{laps: laps, location: CarLocationTrack, last: last}
+}
+
+func TestLapArchive(t *testing.T) {
+	lapCompleted := lapStep{event: true}
+	untimed := LapInfo{LapTimeMs: InvalidSectorTime}
+	type archivedLap struct {
+		number    int
+		lapTimeMs int32
+		stint     int
+		driver    uint16
+	}
+	tests := []struct {
+		name  string
+		steps []lapStep
+		want  []archivedLap
+	}{
+		{
+			name: "event before update",
+			steps: []lapStep{
+				onTrack(0, untimed),
+				lapCompleted,
+				onTrack(1, timedLap(90000)),
+				onTrack(1, timedLap(90000)),
+			},
+			want: []archivedLap{{1, 90000, 1, 0}},
+		},
+		{
+			name: "update before event",
+			steps: []lapStep{
+				onTrack(0, untimed),
+				onTrack(1, timedLap(90000)),
+				lapCompleted,
+				onTrack(1, timedLap(90000)),
+				onTrack(2, timedLap(91000)),
+				lapCompleted,
+				onTrack(2, timedLap(91000)),
+			},
+			want: []archivedLap{{1, 90000, 1, 0}, {2, 91000, 1, 0}},
+		},
+		{
+			name: "delayed last lap",
+			steps: []lapStep{
+				onTrack(1, timedLap(88000)),
+				lapCompleted,
+				onTrack(2, timedLap(88000)),
+				onTrack(2, timedLap(88000)),
+				onTrack(2, timedLap(90000)),
+			},
+			want: []archivedLap{{2, 90000, 1, 0}},
+		},
+		{
+			name: "last lap never timed",
+			steps: []lapStep{
+				onTrack(0, untimed),
+				onTrack(1, untimed),
+				onTrack(1, untimed),
+				onTrack(1, untimed),
+				onTrack(1, untimed),
+				onTrack(1, timedLap(90000)),
+			},
+			want: []archivedLap{{1, InvalidSectorTime, 1, 0}},
+		},
+		{
+			name: "pit exit",
+			steps: []lapStep{
+				{laps: 0, location: CarLocationPitlane, last: untimed},
+				onTrack(0, untimed),
+				onTrack(1, timedLap(95000)),
+				{laps: 2, location: CarLocationPitEntry, last: timedLap(99000)},
+				{laps: 2, location: CarLocationPitlane, last: timedLap(99000)},
+				onTrack(2, timedLap(99000)),
+				onTrack(3, timedLap(92000)),
+			},
+			want: []archivedLap{{1, 95000, 1, 0}, {2, 99000, 1, 0}, {3, 92000, 2, 0}},
+		},
+		{
+			name: "driver swap on track",
+			steps: []lapStep{
+				onTrack(0, untimed),
+				onTrack(1, timedLap(90000)),
+				{laps: 1, location: CarLocationTrack, driver: 1, last: timedLap(90000)},
+				{laps: 2, location: CarLocationTrack, driver: 1, last: LapInfo{LapTimeMs: 91000, DriverIndex: 1}},
+			},
+			want: []archivedLap{{1, 90000, 1, 0}, {2, 91000, 2, 1}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSession(t)
+			for _, step := range test.steps {
+				if step.event {
+					s.handleBroadcastEvent(BroadCastEvent{Type: EventTypeLapCompleted, CarId: 1})
+					continue
+				}
+				s.handleCarUpdate(RealTimeCarUpdate{
+					CarIndex:    1,
+					DriverIndex: step.driver,
+					Laps:        step.laps,
+					CarLocation: step.location,
+					LastLap:     step.last,
+				})
+			}
+			laps := s.Laps(1)
+			if len(laps) != len(test.want) {
+				t.Fatalf("archived %d laps, want %d: %+v", len(laps), len(test.want), laps)
+			}
+			for i, w := range test.want {
+				lap := laps[i]
+				if lap.Number != w.number || lap.LapTimeMs != w.lapTimeMs || lap.Stint != w.stint || lap.DriverIndex != w.driver {
+					t.Errorf("lap %d = number %d time %d stint %d driver %d, want number %d time %d stint %d driver %d",
+						i, lap.Number, lap.LapTimeMs, lap.Stint, lap.DriverIndex, w.number, w.lapTimeMs, w.stint, w.driver)
+				}
+			}
+		})
+	}
+}
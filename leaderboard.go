@@ -0,0 +1,235 @@
+package AccTelemetry
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// leaderboardHistory is the distance in laps for which the progress of a car
+// is kept to compute the time gaps to the cars behind.
+const leaderboardHistory = 2
+
+// LeaderboardEntry is the standing of a car. Gaps in laps count the completed
+// laps the car is behind, gaps in time the time since the car in front was at
+// the same distance.
+type LeaderboardEntry struct {
+	CarIndex       uint16
+	Position       uint16
+	CupCategory    CupCategory
+	ClassPosition  uint16
+	Laps           uint16
+	SplinePosition float32
+	GapToLeader    time.Duration
+	LapsToLeader   int
+	Interval       time.Duration
+	LapsToAhead    int
+	// StartPosition is the position at the start of the session and
+	// PositionsGained the positions gained since, negative if lost.
+	StartPosition   uint16
+	PositionsGained int
+}
+
+// Leaderboard computes the standings from the car updates of a client. It is
+// safe for concurrent use.
+type Leaderboard struct {
+	handlers handlerList[[]LeaderboardEntry]
+	removers []func()
+
+	mu           sync.Mutex
+	trackMeters  int32
+	sessionIndex uint16
+	sessionTime  time.Duration
+	phase        SessionPhase
+	hasUpdate    bool
+	cars         map[uint16]*leaderboardCar
+}
+
+type leaderboardCar struct {
+	update        RealTimeCarUpdate
+	category      CupCategory
+	startPosition uint16
+	history       []progressSample
+}
+
+// progressSample is the distance in laps a car had covered at a session time.
+type progressSample struct {
+	distance    float64
+	sessionTime time.Duration
+}
+
+func NewLeaderboard(client *AccUDPClient) *Leaderboard {
+	l := &Leaderboard{cars: make(map[uint16]*leaderboardCar)}
+	l.removers = []func(){
+		client.OnTrackData(l.handleTrackData),
+		client.OnEntryList(l.handleEntryList),
+		client.OnEntryListCar(l.handleEntryListCar),
+		client.OnRealtimeUpdate(l.handleRealtimeUpdate),
+		client.OnCarUpdate(l.handleCarUpdate),
+	}
+	return l
+}
+
+// OnUpdate is called with the standings on every realtime update.
+func (l *Leaderboard) OnUpdate(fn func([]LeaderboardEntry)) (remove func()) {
+	return l.handlers.add(fn)
+}
+
+// Close stops updating the leaderboard.
+func (l *Leaderboard) Close() {
+	for _, remove := range l.removers {
+		remove()
+	}
+}
+
+// Entries returns the current standings ordered by position.
+func (l *Leaderboard) Entries() []LeaderboardEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries()
+}
+
+func (l *Leaderboard) car(id uint16) *leaderboardCar {
+	car, ok := l.cars[id]
+	if !ok {
+		car = &leaderboardCar{}
+		l.cars[id] = car
+	}
+	return car
+}
+
+func (l *Leaderboard) handleTrackData(track TrackData) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.trackMeters = track.Meters
+}
+
+// handleEntryList drops the cars that left the session.
+func (l *Leaderboard) handleEntryList(entryList EntryList) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id := range l.cars {
+		if !slices.Contains(entryList, id) {
+			delete(l.cars, id)
+		}
+	}
+}
+
+func (l *Leaderboard) handleEntryListCar(info CarInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.car(info.Id).category = info.CupCategory
+}
+
+func (l *Leaderboard) handleRealtimeUpdate(update RealTimeUpdate) {
+	l.mu.Lock()
+	if l.hasUpdate && l.sessionIndex != update.SessionIndex {
+		for id, car := range l.cars {
+			l.cars[id] = &leaderboardCar{category: car.category}
+		}
+	}
+	l.sessionIndex = update.SessionIndex
+	l.sessionTime = update.SessionTime
+	l.phase = update.Phase
+	l.hasUpdate = true
+	entries := l.entries()
+	l.mu.Unlock()
+	l.handlers.call(entries)
+}
+
+func (l *Leaderboard) handleCarUpdate(update RealTimeCarUpdate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	car := l.car(update.CarIndex)
+	car.update = update
+	// the grid is final once the session is running
+	if l.phase < SessionPhaseSession || car.startPosition == 0 {
+		car.startPosition = update.Position
+	}
+	sample := progressSample{distance: progress(update), sessionTime: l.sessionTime}
+	if n := len(car.history); n > 0 && car.history[n-1].sessionTime == sample.sessionTime {
+		car.history[n-1] = sample
+	} else {
+		car.history = append(car.history, sample)
+	}
+	oldest := 0
+	for oldest < len(car.history) && car.history[oldest].distance < sample.distance-leaderboardHistory {
+		oldest++
+	}
+	car.history = car.history[oldest:]
+}
+
+func progress(update RealTimeCarUpdate) float64 {
+	return float64(update.Laps) + float64(update.SplinePosition)
+}
+
+// entries computes the standings. l.mu must be held.
+func (l *Leaderboard) entries() []LeaderboardEntry {
+	cars := make([]*leaderboardCar, 0, len(l.cars))
+	for _, car := range l.cars {
+		if car.update.Position > 0 {
+			cars = append(cars, car)
+		}
+	}
+	slices.SortFunc(cars, func(a, b *leaderboardCar) int {
+		return int(a.update.Position) - int(b.update.Position)
+	})
+	entries := make([]LeaderboardEntry, len(cars))
+	for i, car := range cars {
+		entries[i] = LeaderboardEntry{
+			CarIndex:        car.update.CarIndex,
+			Position:        car.update.Position,
+			CupCategory:     car.category,
+			ClassPosition:   car.update.CupPosition,
+			Laps:            car.update.Laps,
+			SplinePosition:  car.update.SplinePosition,
+			StartPosition:   car.startPosition,
+			PositionsGained: int(car.startPosition) - int(car.update.Position),
+		}
+		if i == 0 {
+			continue
+		}
+		entries[i].GapToLeader, entries[i].LapsToLeader = l.gap(cars[0], car)
+		entries[i].Interval, entries[i].LapsToAhead = l.gap(cars[i-1], car)
+	}
+	return entries
+}
+
+// gap returns how long ago ahead was where behind is now and how many laps
+// behind is down.
+func (l *Leaderboard) gap(ahead, behind *leaderboardCar) (time.Duration, int) {
+	distance := progress(behind.update)
+	laps := max(int(progress(ahead.update)-distance), 0)
+	// behind was at distance when its latest sample was taken, which may be
+	// an update interval before the current session time
+	now := l.sessionTime
+	if n := len(behind.history); n > 0 {
+		now = behind.history[n-1].sessionTime
+	}
+	history := ahead.history
+	i, _ := slices.BinarySearchFunc(history, distance, func(sample progressSample, distance float64) int {
+		switch {
+		case sample.distance < distance:
+			return -1
+		case sample.distance > distance:
+			return 1
+		}
+		return 0
+	})
+	if i > 0 && i < len(history) {
+		before, after := history[i-1], history[i]
+		fraction := (distance - before.distance) / (after.distance - before.distance)
+		passed := before.sessionTime + time.Duration(fraction*float64(after.sessionTime-before.sessionTime))
+		return max(now-passed, 0), laps
+	}
+	if i < len(history) && history[i].distance == distance {
+		return max(now-history[i].sessionTime, 0), laps
+	}
+	// no history covers the distance, estimate it from the speed
+	if behind.update.Kmh == 0 || l.trackMeters == 0 {
+		return 0, laps
+	}
+	meters := (progress(ahead.update) - distance) * float64(l.trackMeters)
+	seconds := meters / (float64(behind.update.Kmh) / 3.6)
+	return max(time.Duration(seconds*float64(time.Second)), 0), laps
+}
@@ -0,0 +1,123 @@
+package AccTelemetry
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLeaderboard(t *testing.T, trackMeters int32) *Leaderboard {
+	t.Helper()
+	l := NewLeaderboard(newReplayClient(t, WithoutChannels()))
+	t.Cleanup(l.Close)
+	l.handleTrackData(TrackData{Meters: trackMeters})
+	return l
+}
+
+func leaderboardUpdate(carIndex uint16, position uint16, laps uint16, spline float32) RealTimeCarUpdate {
+	return RealTimeCarUpdate{CarIndex: carIndex, Position: position, Laps: laps, SplinePosition: spline, Kmh: 180}
+}
+
+func assertDuration(t *testing.T, name string, got, want time.Duration) {
+	t.Helper()
+	if diff := got - want; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestLeaderboardGapInterpolation(t *testing.T) {
+	tests := []struct {
+		name   string
+		behind float32
+		want   time.Duration
+	}{
+		// the car behind is where the leader was one sample earlier
+		{"on a sample", 0, time.Second},
+		// the car behind is halfway between the last two samples of the leader
+		{"between samples", 0.05, 500 * time.Millisecond},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLeaderboard(t, 1000)
+			for k := 0; k < 5; k++ {
+				l.handleRealtimeUpdate(RealTimeUpdate{SessionTime: time.Duration(k) * time.Second, Phase: SessionPhaseSession})
+				l.handleCarUpdate(leaderboardUpdate(1, 1, 0, 0.1*float32(k)+0.2))
+				l.handleCarUpdate(leaderboardUpdate(2, 2, 0, 0.1*float32(k)+test.behind+0.1))
+			}
+			entries := l.Entries()
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+			assertDuration(t, "GapToLeader", entries[1].GapToLeader, test.want)
+			assertDuration(t, "Interval", entries[1].Interval, test.want)
+			if entries[1].LapsToLeader != 0 {
+				t.Errorf("LapsToLeader = %d, want 0", entries[1].LapsToLeader)
+			}
+		})
+	}
+}
+
+func TestLeaderboardGapFromSpeed(t *testing.T) {
+	l := newTestLeaderboard(t, 1000)
+	l.handleRealtimeUpdate(RealTimeUpdate{SessionTime: time.Minute, Phase: SessionPhaseSession})
+	l.handleCarUpdate(leaderboardUpdate(1, 1, 2, 0.5))
+	l.handleCarUpdate(leaderboardUpdate(2, 2, 2, 0.4))
+	l.handleCarUpdate(leaderboardUpdate(3, 3, 0, 0.4))
+	entries := l.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	// 100m at 180km/h
+	assertDuration(t, "GapToLeader", entries[1].GapToLeader, 2*time.Second)
+	if entries[2].LapsToLeader != 2 || entries[2].LapsToAhead != 2 {
+		t.Errorf("laps down = %d/%d, want 2/2", entries[2].LapsToLeader, entries[2].LapsToAhead)
+	}
+}
+
+func TestLeaderboardPositionsGained(t *testing.T) {
+	l := newTestLeaderboard(t, 1000)
+	l.handleRealtimeUpdate(RealTimeUpdate{Phase: SessionPhasePreSession})
+	l.handleCarUpdate(leaderboardUpdate(1, 2, 0, 0))
+	l.handleCarUpdate(leaderboardUpdate(2, 1, 0, 0))
+	// the grid may still change before the session starts
+	l.handleCarUpdate(leaderboardUpdate(1, 3, 0, 0))
+	l.handleCarUpdate(leaderboardUpdate(3, 2, 0, 0))
+	l.handleRealtimeUpdate(RealTimeUpdate{Phase: SessionPhaseSession})
+	l.handleCarUpdate(leaderboardUpdate(1, 1, 0, 0.3))
+	l.handleCarUpdate(leaderboardUpdate(2, 3, 0, 0.1))
+	l.handleCarUpdate(leaderboardUpdate(3, 2, 0, 0.2))
+
+	want := map[uint16]struct {
+		start  uint16
+		gained int
+	}{
+		1: {3, 2},
+		2: {1, -2},
+		3: {2, 0},
+	}
+	for _, entry := range l.Entries() {
+		w := want[entry.CarIndex]
+		if entry.StartPosition != w.start || entry.PositionsGained != w.gained {
+			t.Errorf("car %d start %d gained %d, want start %d gained %d",
+				entry.CarIndex, entry.StartPosition, entry.PositionsGained, w.start, w.gained)
+		}
+	}
+}
+
+func TestLeaderboardDropsCarsLeavingTheEntryList(t *testing.T) {
+	l := newTestLeaderboard(t, 1000)
+	l.handleRealtimeUpdate(RealTimeUpdate{Phase: SessionPhaseSession})
+	l.handleCarUpdate(leaderboardUpdate(1, 1, 0, 0.3))
+	l.handleCarUpdate(leaderboardUpdate(2, 2, 0, 0.2))
+	l.handleCarUpdate(leaderboardUpdate(3, 3, 0, 0.1))
+	l.handleEntryList(EntryList{1, 3})
+	// car 3 moves up into the position of the car that left
+	l.handleCarUpdate(leaderboardUpdate(3, 2, 0, 0.15))
+
+	entries := l.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].CarIndex != 1 || entries[1].CarIndex != 3 || entries[1].Position != 2 {
+		t.Errorf("entries = %+v, want car 1 and car 3 in P2", entries)
+	}
+}
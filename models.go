@@ -1,6 +1,9 @@
 package AccTelemetry
 
-import "time"
+import (
+	"github.com/Soemii/goptional"
+	"time"
+)
 
 type OutboundMessage byte
 type InboundMessage byte
@@ -25,6 +28,26 @@ type TrackData struct {
 	HudPages   []string
 }
 
+func (m InboundMessage) String() string {
+	switch m {
+	case InboundMessageRegistrationResult:
+		return "RegistrationResult"
+	case InboundMessageRealtimeUpdate:
+		return "RealtimeUpdate"
+	case InboundMessageRealtimeCarUpdate:
+		return "RealtimeCarUpdate"
+	case InboundMessageEntryList:
+		return "EntryList"
+	case InboundMessageTrackData:
+		return "TrackData"
+	case InboundMessageEntryListCar:
+		return "EntryListCar"
+	case InboundMessageBroadcastingEvent:
+		return "BroadcastingEvent"
+	}
+	return "Unknown"
+}
+
 type CarInfo struct {
 	Id              uint16
 	Model           CarModel
@@ -37,12 +60,61 @@ type CarInfo struct {
 }
 
 func (c CarInfo) GetCurrentDriver() DriverInfo {
-	if c.CurrentDriverId > int8(len(c.Drivers)) {
+	if c.CurrentDriverId >= 0 && int(c.CurrentDriverId) < len(c.Drivers) {
 		return c.Drivers[c.CurrentDriverId]
 	}
 	return DriverInfo{}
 }
 
+type RegistrationResult struct {
+	ConnectionId int32
+	Success      bool
+	ReadOnly     bool
+	ErrorMessage string
+}
+
+type RegistrationRequest struct {
+	ProtocolVersion          byte
+	DisplayName              string
+	ConnectionPassword       string
+	MsRealtimeUpdateInterval int32
+	CommandPassword          string
+}
+
+type UnregisterRequest struct {
+	ConnectionId int32
+}
+
+type EntryListRequest struct {
+	ConnectionId int32
+}
+
+type TrackDataRequest struct {
+	ConnectionId int32
+}
+
+type HudPageRequest struct {
+	ConnectionId int32
+	HudPage      string
+}
+
+type FocusRequest struct {
+	ConnectionId int32
+	CarIndex     goptional.Optional[uint16]
+	// CameraSet and Camera are only sent if both are present.
+	CameraSet goptional.Optional[string]
+	Camera    goptional.Optional[string]
+}
+
+type InstantReplayRequest struct {
+	ConnectionId           int32
+	StartSessionTime       float32
+	DurationMS             float32
+	InitialFocusedCarIndex int32
+	InitialCameraSet       string
+	InitialCamera          string
+}
+
 type BroadCastEvent struct {
 	Type   EventType
 	Msg    string
@@ -51,7 +123,9 @@ type BroadCastEvent struct {
 }
 
 type LapInfo struct {
-	LapTimeMs      int32
+	LapTimeMs int32
+	// Splits holds the sector times. Decoded laps always have at least three,
+	// ACC sends fewer for laps in progress and those are padded with zeros.
 	Splits         []int32
 	CarIndex       uint16
 	DriverIndex    uint16
@@ -60,6 +134,22 @@ type LapInfo struct {
 	LapType        LapType
 }
 
+// LapTime returns the lap time or false if ACC did not time the lap.
+func (l LapInfo) LapTime() (time.Duration, bool) {
+	if l.LapTimeMs == InvalidSectorTime || l.LapTimeMs <= 0 {
+		return 0, false
+	}
+	return time.Duration(l.LapTimeMs) * time.Millisecond, true
+}
+
+// Sector returns the time of sector i or false if it was not timed.
+func (l LapInfo) Sector(i int) (time.Duration, bool) {
+	if i < 0 || i >= len(l.Splits) || l.Splits[i] == InvalidSectorTime || l.Splits[i] <= 0 {
+		return 0, false
+	}
+	return time.Duration(l.Splits[i]) * time.Millisecond, true
+}
+
 type DriverInfo struct {
 	FirstName   string
 	LastName    string
@@ -90,12 +180,17 @@ type RealTimeCarUpdate struct {
 }
 
 type RealTimeUpdate struct {
-	EventIndex           uint16
-	SessionIndex         uint16
-	Phase                SessionPhase
-	SessionTime          time.Time     //TODO: CHECK IF TYPE CORRECT
-	RemainingTime        time.Duration //TODO: CHECK IF TYPE CORRECT
-	TimeOfDay            time.Time     //TODO: CHECK IF TYPE CORRECT
+	EventIndex   uint16
+	SessionIndex uint16
+	Phase        SessionPhase
+	// SessionTime is the time elapsed since the session started.
+	SessionTime time.Duration
+	// RemainingTime is the time left in the session at the moment shown by
+	// ACC, i.e. ReplayRemainingTime while a replay is playing and
+	// SessionRemainingTime otherwise.
+	RemainingTime time.Duration
+	// TimeOfDay is the in-game time of day as duration since midnight.
+	TimeOfDay            time.Duration
 	RainLevel            float32
 	Clouds               float32
 	Wetness              float32
@@ -106,14 +201,16 @@ type RealTimeUpdate struct {
 	ActiveCameraSet      string
 	ActiveCamera         string
 	IsReplaying          bool
-	ReplaySessionTime    time.Time
+	ReplaySessionTime    time.Duration
 	ReplayRemainingTime  time.Duration
-	SessionRemainingTime time.Duration //TODO: CHECK IF TYPE CORRECT
-	SessionEndTime       time.Time     //TODO: CHECK IF TYPE CORRECT
-	SessionType          SessionType
-	AmbientTemp          byte
-	TrackTemp            byte
-	CurrentHudPage       string
+	SessionRemainingTime time.Duration
+	// SessionEndTime is the time until the session ends as sent by ACC. It
+	// becomes negative once the session is over time.
+	SessionEndTime time.Duration
+	SessionType    SessionType
+	AmbientTemp    byte
+	TrackTemp      byte
+	CurrentHudPage string
 }
 
 //CONSTS
@@ -192,6 +289,9 @@ const (
 	SessionTypeHotstint        SessionType = 12
 	SessionTypeHotlapSuperpole SessionType = 13
 	SessionTypeReplay          SessionType = 14
+	// SessionTypeUnknown is used for shared memory session types the
+	// broadcasting protocol has no equivalent for.
+	SessionTypeUnknown SessionType = 255
 )
 
 const (
@@ -296,22 +396,54 @@ const (
 //TODO: CHECK
 
 const (
-	CarModelMercedes       CarModel = 1
-	CarModelFerrari        CarModel = 2
-	CarModelLexus          CarModel = 15
-	CarModelLamborghini    CarModel = 16
-	CarModelAudi           CarModel = 19
-	CarModelAstonMartin    CarModel = 20
-	CarModelPorsche        CarModel = 23
-	CarModelAlpineGT4      CarModel = 50
-	CarModelAstonMartinGT4 CarModel = 51
-	CarModelBMWGT4         CarModel = 53
-	CarModelChevroletGT4   CarModel = 55
-	CarModelKTMGT4         CarModel = 57
-	CarModelMaseratiGT4    CarModel = 58
-	CarModelMcLarenGT4     CarModel = 59
-	CarModelMercedesGT4    CarModel = 60
-	CarModelPorscheGT4     CarModel = 61
+	CarModelPorsche991GT3R          CarModel = 0
+	CarModelMercedes                CarModel = 1
+	CarModelFerrari                 CarModel = 2
+	CarModelAudiR8LMS               CarModel = 3
+	CarModelLamborghiniHuracan      CarModel = 4
+	CarModelMcLaren650S             CarModel = 5
+	CarModelNissanGTR2018           CarModel = 6
+	CarModelBMWM6                   CarModel = 7
+	CarModelBentley2018             CarModel = 8
+	CarModelPorsche991IICup         CarModel = 9
+	CarModelNissanGTR2017           CarModel = 10
+	CarModelBentley2016             CarModel = 11
+	CarModelAstonMartinV12          CarModel = 12
+	CarModelLamborghiniGallardoREX  CarModel = 13
+	CarModelJaguarG3                CarModel = 14
+	CarModelLexus                   CarModel = 15
+	CarModelLamborghini             CarModel = 16
+	CarModelHondaNSX                CarModel = 17
+	CarModelLamborghiniHuracanST    CarModel = 18
+	CarModelAudi                    CarModel = 19
+	CarModelAstonMartin             CarModel = 20
+	CarModelHondaNSXEvo             CarModel = 21
+	CarModelMcLaren720S             CarModel = 22
+	CarModelPorsche                 CarModel = 23
+	CarModelFerrariEvo              CarModel = 24
+	CarModelMercedesEvo             CarModel = 25
+	CarModelFerrariChallengeEvo     CarModel = 26
+	CarModelBMWM2CS                 CarModel = 27
+	CarModelPorsche992Cup           CarModel = 28
+	CarModelLamborghiniHuracanSTEvo CarModel = 29
+	CarModelBMWM4                   CarModel = 30
+	CarModelAudiEvo2                CarModel = 31
+	CarModelFerrari296              CarModel = 32
+	CarModelLamborghiniEvo2         CarModel = 33
+	CarModelPorsche992GT3R          CarModel = 34
+	CarModelMcLaren720SEvo          CarModel = 35
+	CarModelFordMustang             CarModel = 36
+	CarModelAlpineGT4               CarModel = 50
+	CarModelAstonMartinGT4          CarModel = 51
+	CarModelAudiGT4                 CarModel = 52
+	CarModelBMWGT4                  CarModel = 53
+	CarModelChevroletGT4            CarModel = 55
+	CarModelGinettaGT4              CarModel = 56
+	CarModelKTMGT4                  CarModel = 57
+	CarModelMaseratiGT4             CarModel = 58
+	CarModelMcLarenGT4              CarModel = 59
+	CarModelMercedesGT4             CarModel = 60
+	CarModelPorscheGT4              CarModel = 61
 )
 
 const (
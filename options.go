@@ -0,0 +1,121 @@
+package AccTelemetry
+
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	DefaultUpdateInterval = 250 * time.Millisecond
+	DefaultTimeout        = 5 * time.Second
+	DefaultChannelBuffer  = 64
+)
+
+type Option func(c *AccUDPClient)
+
+// WithCommandPassword sets the password required to send commands such as
+// RequestFocusedCar. Without it the connection is read-only.
+func WithCommandPassword(commandPassword string) Option {
+	return func(c *AccUDPClient) {
+		c.commandPassword = commandPassword
+	}
+}
+
+// WithUpdateInterval sets the interval in which ACC sends realtime updates.
+func WithUpdateInterval(interval time.Duration) Option {
+	return func(c *AccUDPClient) {
+		c.updateInterval = interval
+	}
+}
+
+// WithTimeout sets how long reads and the registration wait for ACC.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *AccUDPClient) {
+		c.timeOutDuration = timeout
+	}
+}
+
+// WithChannelBuffer sets the buffer size of every event channel.
+func WithChannelBuffer(size int) Option {
+	return func(c *AccUDPClient) {
+		c.channelBuffer = size
+	}
+}
+
+// WithLogger sets the structured logger of the client. By default nothing is
+// logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *AccUDPClient) {
+		c.logger = logger
+	}
+}
+
+// WithLocalAddr binds the client to a local address such as "0.0.0.0:9001".
+func WithLocalAddr(localAddress string) Option {
+	return func(c *AccUDPClient) {
+		c.localAddress = localAddress
+	}
+}
+
+// WithHandler subscribes h before the client is returned, so no event can be
+// missed.
+func WithHandler(h Handler) Option {
+	return func(c *AccUDPClient) {
+		c.initialHandlers = append(c.initialHandlers, h)
+	}
+}
+
+// WithoutChannels leaves all event channels nil. Use it when the events are
+// consumed through handlers only, so undrained channels cannot stall the
+// client.
+func WithoutChannels() Option {
+	return func(c *AccUDPClient) {
+		c.withoutChannels = true
+	}
+}
+
+// WithBackpressure sets the policy used when the channel of stream is full.
+// By default the connection state and registration streams drop their oldest
+// events and every other stream blocks.
+func WithBackpressure(stream Stream, policy BackpressurePolicy) Option {
+	return func(c *AccUDPClient) {
+		if stream < streamCount {
+			c.policies[stream] = policy
+		}
+	}
+}
+
+// WithRecorder appends every datagram sent and received by the client to
+// the capture w. The passwords of registrations are removed, and the track
+// and session are added as metadata once ACC reports them. The client does
+// not close w.
+func WithRecorder(w *CaptureWriter) Option {
+	return func(c *AccUDPClient) {
+		c.recorder = w
+	}
+}
+
+// WithEntryListRefresh sets the minimum time between entry list requests the
+// client sends when car updates of unknown cars arrive. Zero disables the
+// automatic requests.
+func WithEntryListRefresh(interval time.Duration) Option {
+	return func(c *AccUDPClient) {
+		c.entryListRefresh = interval
+	}
+}
+
+// WithDialer replaces the UDP socket of the client with the transport returned
+// by dial, e.g. a relay connection wrapped with NewStreamTransport.
+func WithDialer(dial Dialer) Option {
+	return func(c *AccUDPClient) {
+		c.dialer = dial
+	}
+}
+
+// WithTransport connects the client through t. As the transport is closed on
+// disconnect, the client can only be connected once.
+func WithTransport(t Transport) Option {
+	return WithDialer(func() (Transport, error) {
+		return t, nil
+	})
+}
@@ -0,0 +1,224 @@
+package AccTelemetry
+
+import "time"
+
+// rawPhysics mirrors SPageFilePhysics. Fields ACC does not fill are kept to
+// preserve the layout.
+type rawPhysics struct {
+	PacketId            int32
+	Gas                 float32
+	Brake               float32
+	Fuel                float32
+	Gear                int32
+	Rpms                int32
+	SteerAngle          float32
+	SpeedKmh            float32
+	Velocity            [3]float32
+	AccG                [3]float32
+	WheelSlip           [4]float32
+	WheelLoad           [4]float32
+	WheelsPressure      [4]float32
+	WheelAngularSpeed   [4]float32
+	TyreWear            [4]float32
+	TyreDirtyLevel      [4]float32
+	TyreCoreTemperature [4]float32
+	CamberRad           [4]float32
+	SuspensionTravel    [4]float32
+	Drs                 float32
+	TC                  float32
+	Heading             float32
+	Pitch               float32
+	Roll                float32
+	CgHeight            float32
+	CarDamage           [5]float32
+	NumberOfTyresOut    int32
+	PitLimiterOn        int32
+	ABS                 float32
+	KersCharge          float32
+	KersInput           float32
+	AutoShifterOn       int32
+	RideHeight          [2]float32
+	TurboBoost          float32
+	Ballast             float32
+	AirDensity          float32
+	AirTemp             float32
+	RoadTemp            float32
+	LocalAngularVel     [3]float32
+	FinalFF             float32
+	PerformanceMeter    float32
+	EngineBrake         int32
+	ErsRecoveryLevel    int32
+	ErsPowerLevel       int32
+	ErsHeatCharging     int32
+	ErsIsCharging       int32
+	KersCurrentKJ       float32
+	DrsAvailable        int32
+	DrsEnabled          int32
+	BrakeTemp           [4]float32
+	Clutch              float32
+	TyreTempI           [4]float32
+	TyreTempM           [4]float32
+	TyreTempO           [4]float32
+	IsAIControlled      int32
+	TyreContactPoint    [4][3]float32
+	TyreContactNormal   [4][3]float32
+	TyreContactHeading  [4][3]float32
+	BrakeBias           float32
+	LocalVelocity       [3]float32
+	P2PActivations      int32
+	P2PStatus           int32
+	CurrentMaxRpm       int32
+	Mz                  [4]float32
+	Fx                  [4]float32
+	Fy                  [4]float32
+	SlipRatio           [4]float32
+	SlipAngle           [4]float32
+	TCInAction          int32
+	ABSInAction         int32
+	SuspensionDamage    [4]float32
+	TyreTemp            [4]float32
+	WaterTemp           float32
+	BrakePressure       [4]float32
+	FrontBrakeCompound  int32
+	RearBrakeCompound   int32
+	PadLife             [4]float32
+	DiscLife            [4]float32
+	IgnitionOn          int32
+	StarterEngineOn     int32
+	IsEngineRunning     int32
+	KerbVibration       float32
+	SlipVibrations      float32
+	GVibrations         float32
+	ABSVibrations       float32
+}
+
+// PhysicsPageSize is the size of the physics page in bytes.
+const PhysicsPageSize = 800
+
+// Physics is the physics page of the player's car. Per-wheel arrays are
+// indexed by WheelFrontLeft to WheelRearRight.
+type Physics struct {
+	PacketId   int32
+	Throttle   float32
+	Brake      float32
+	Clutch     float32
+	SteerAngle float32
+	// Gear is -1 for reverse, 0 for neutral and 1 for the first gear.
+	Gear     int32
+	Rpm      int32
+	SpeedKmh float32
+	Fuel     float32
+	Velocity Vector3
+	// LocalVelocity is the velocity in car coordinates.
+	LocalVelocity Vector3
+	// AccG is the acceleration in g.
+	AccG    Vector3
+	Heading float32
+	Pitch   float32
+	Roll    float32
+
+	TyrePressure        [4]float32
+	TyreCoreTemperature [4]float32
+	// TyreWear is not filled by ACC yet.
+	TyreWear         [4]float32
+	WheelSlip        [4]float32
+	BrakeTemperature [4]float32
+	BrakePressure    [4]float32
+	PadLife          [4]float32
+	DiscLife         [4]float32
+	SuspensionTravel [4]float32
+
+	// TC and ABS are the slip ratio limits of traction control and ABS set
+	// by the current levels, not their activity.
+	TC  float32
+	ABS float32
+	// TCInAction and ABSInAction report whether traction control and ABS are
+	// currently intervening.
+	TCInAction         bool
+	ABSInAction        bool
+	BrakeBias          float32
+	TurboBoost         float32
+	AirTemp            float32
+	RoadTemp           float32
+	WaterTemp          float32
+	CarDamage          [5]float32
+	FrontBrakeCompound int32
+	RearBrakeCompound  int32
+
+	PitLimiterOn    bool
+	AutoShifterOn   bool
+	IsAIControlled  bool
+	IgnitionOn      bool
+	StarterEngineOn bool
+	IsEngineRunning bool
+}
+
+// ReadPhysics reads the physics page from segment.
+func ReadPhysics(segment Segment) (physics Physics, err error) {
+	raw, err := readPage[rawPhysics](segment, "physics")
+	if err != nil {
+		return
+	}
+	return raw.physics(), nil
+}
+
+// NewPhysicsPoller polls the physics page every interval and passes on
+// every new frame.
+func NewPhysicsPoller(segment Segment, interval time.Duration) *Poller[Physics] {
+	return newPoller(interval, func() (Physics, error) {
+		return ReadPhysics(segment)
+	}, func(frame Physics) int32 {
+		return frame.PacketId
+	})
+}
+
+func (r *rawPhysics) physics() Physics {
+	return Physics{
+		PacketId:            r.PacketId,
+		Throttle:            r.Gas,
+		Brake:               r.Brake,
+		Clutch:              r.Clutch,
+		SteerAngle:          r.SteerAngle,
+		Gear:                r.Gear - 1,
+		Rpm:                 r.Rpms,
+		SpeedKmh:            r.SpeedKmh,
+		Fuel:                r.Fuel,
+		Velocity:            vector3(r.Velocity),
+		LocalVelocity:       vector3(r.LocalVelocity),
+		AccG:                vector3(r.AccG),
+		Heading:             r.Heading,
+		Pitch:               r.Pitch,
+		Roll:                r.Roll,
+		TyrePressure:        r.WheelsPressure,
+		TyreCoreTemperature: r.TyreCoreTemperature,
+		TyreWear:            r.TyreWear,
+		WheelSlip:           r.WheelSlip,
+		BrakeTemperature:    r.BrakeTemp,
+		BrakePressure:       r.BrakePressure,
+		PadLife:             r.PadLife,
+		DiscLife:            r.DiscLife,
+		SuspensionTravel:    r.SuspensionTravel,
+		TC:                  r.TC,
+		ABS:                 r.ABS,
+		TCInAction:          r.TCInAction != 0,
+		ABSInAction:         r.ABSInAction != 0,
+		BrakeBias:           r.BrakeBias,
+		TurboBoost:          r.TurboBoost,
+		AirTemp:             r.AirTemp,
+		RoadTemp:            r.RoadTemp,
+		WaterTemp:           r.WaterTemp,
+		CarDamage:           r.CarDamage,
+		FrontBrakeCompound:  r.FrontBrakeCompound,
+		RearBrakeCompound:   r.RearBrakeCompound,
+		PitLimiterOn:        r.PitLimiterOn != 0,
+		AutoShifterOn:       r.AutoShifterOn != 0,
+		IsAIControlled:      r.IsAIControlled != 0,
+		IgnitionOn:          r.IgnitionOn != 0,
+		StarterEngineOn:     r.StarterEngineOn != 0,
+		IsEngineRunning:     r.IsEngineRunning != 0,
+	}
+}
+
+func vector3(v [3]float32) Vector3 {
+	return Vector3{X: v[0], Y: v[1], Z: v[2]}
+}
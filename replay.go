@@ -0,0 +1,376 @@
+package AccTelemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReplayAsFastAsPossible plays a capture without waiting between datagrams.
+const ReplayAsFastAsPossible float64 = 0
+
+var ErrSessionTimeNotFound = errors.New("session time not found in capture")
+
+// ReplaySource plays the inbound datagrams of a capture through an
+// AccUDPClient, so handlers, channels and subscribers receive the same events
+// as from a live session. The client must not be connected to ACC at the
+// same time.
+//
+// Use Run to play in real time or at another speed, or Step to deliver one
+// datagram at a time. Both may be called again to resume the playback until
+// Close is called.
+type ReplaySource struct {
+	client *AccUDPClient
+	r      io.ReaderAt
+	size   int64
+	closer io.Closer
+	header CaptureHeader
+	index  []replayEntry
+
+	mu         sync.Mutex
+	next       int
+	speed      float64
+	generation int
+	started    bool
+	closed     bool
+
+	// deliverMu serializes the deliveries of Run and Step and lets Close
+	// wait for the one in progress.
+	deliverMu sync.Mutex
+}
+
+type replayEntry struct {
+	pos    int64
+	offset time.Duration
+	// sessionTime is set for realtime updates only.
+	sessionTime    time.Duration
+	hasSessionTime bool
+}
+
+func OpenReplay(path string, client *AccUDPClient) (*ReplaySource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	source, err := NewReplaySource(file, info.Size(), client)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	source.closer = file
+	return source, nil
+}
+
+// NewReplaySource indexes the capture in r. A partial record at the end of
+// the capture is ignored.
+func NewReplaySource(r io.ReaderAt, size int64, client *AccUDPClient) (*ReplaySource, error) {
+	reader, err := NewCaptureReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	source := &ReplaySource{client: client, r: r, size: size, header: reader.Header(), speed: 1}
+	for {
+		pos := reader.pos
+		var record CaptureRecord
+		record, err = reader.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Direction != CaptureInbound {
+			continue
+		}
+		entry := replayEntry{pos: pos, offset: record.Offset}
+		if len(record.Data) > 0 && InboundMessage(record.Data[0]) == InboundMessageRealtimeUpdate {
+			if update, decodeErr := DecodeRealtimeUpdate(record.Data); decodeErr == nil {
+				entry.sessionTime = update.SessionTime
+				entry.hasSessionTime = true
+			}
+		}
+		source.index = append(source.index, entry)
+	}
+	// the header as completed by the metadata records
+	source.header = reader.Header()
+	return source, nil
+}
+
+func (s *ReplaySource) Header() CaptureHeader {
+	return s.header
+}
+
+// Len returns the number of inbound datagrams in the capture.
+func (s *ReplaySource) Len() int {
+	return len(s.index)
+}
+
+// SetSpeed sets the playback speed of Run, e.g. 1 for real time, 10 for ten
+// times faster or ReplayAsFastAsPossible.
+func (s *ReplaySource) SetSpeed(speed float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speed = max(speed, 0)
+	s.generation++
+}
+
+// Seek moves to the first realtime update at or after sessionTime.
+func (s *ReplaySource) Seek(sessionTime time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, entry := range s.index {
+		if entry.hasSessionTime && entry.sessionTime >= sessionTime {
+			s.next = i
+			s.generation++
+			return nil
+		}
+	}
+	return ErrSessionTimeNotFound
+}
+
+// Step delivers the next datagram immediately. It returns io.EOF at the end
+// of the capture.
+func (s *ReplaySource) Step() (err error) {
+	s.mu.Lock()
+	if err = s.ensureStarted(); err != nil {
+		s.mu.Unlock()
+		return
+	}
+	if s.next >= len(s.index) {
+		s.mu.Unlock()
+		return io.EOF
+	}
+	entry := s.index[s.next]
+	s.next++
+	s.mu.Unlock()
+	return s.deliver(entry)
+}
+
+// Run plays the capture from the current position at the configured speed
+// until the end of the capture or until ctx is cancelled. It leaves the client
+// running, so cancelling ctx pauses the playback and a later Run or Step
+// resumes it.
+func (s *ReplaySource) Run(ctx context.Context) (err error) {
+	s.mu.Lock()
+	err = s.ensureStarted()
+	stop := s.client.stop
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	generation := -1
+	var anchorWall time.Time
+	var anchorOffset time.Duration
+	for {
+		s.mu.Lock()
+		if s.next >= len(s.index) {
+			s.mu.Unlock()
+			return nil
+		}
+		entry := s.index[s.next]
+		speed := s.speed
+		if generation != s.generation {
+			generation = s.generation
+			anchorWall = time.Now()
+			anchorOffset = entry.offset
+		}
+		s.mu.Unlock()
+
+		if speed != ReplayAsFastAsPossible {
+			wait := time.Until(anchorWall.Add(time.Duration(float64(entry.offset-anchorOffset) / speed)))
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-stop:
+					timer.Stop()
+					return ErrClientClosed
+				case <-timer.C:
+				}
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if generation != s.generation {
+			// seeked or changed speed while waiting
+			s.mu.Unlock()
+			continue
+		}
+		s.next++
+		s.mu.Unlock()
+		if err = s.deliver(entry); err != nil {
+			return
+		}
+	}
+}
+
+// Close stops the delivery to the client, closes its event channels and the
+// file opened by OpenReplay. It waits for a delivery in progress; Run and Step
+// return ErrClientClosed afterwards.
+func (s *ReplaySource) Close() (err error) {
+	s.mu.Lock()
+	started := s.started
+	s.started = false
+	s.closed = true
+	s.mu.Unlock()
+	if started {
+		// unblocks a delivery waiting for a full channel
+		s.client.stopOnce.Do(func() { close(s.client.stop) })
+	}
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+	if started {
+		close(s.client.done)
+		err = s.client.shutdown()
+	}
+	s.client.closeChannels()
+	if s.closer != nil {
+		err = errors.Join(err, s.closer.Close())
+		s.closer = nil
+	}
+	return
+}
+
+func (s *ReplaySource) ensureStarted() error {
+	if s.closed || s.client.closed {
+		return ErrClientClosed
+	}
+	if !s.started {
+		s.client.start()
+		s.started = true
+	}
+	return nil
+}
+
+func (s *ReplaySource) deliver(entry replayEntry) error {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return ErrClientClosed
+	}
+	record, err := readCaptureRecord(io.NewSectionReader(s.r, entry.pos, s.size-entry.pos))
+	if err != nil {
+		return err
+	}
+	s.client.dispatch(record.Data)
+	return nil
+}
+
+// NewCaptureTransport returns a Transport reading the inbound datagrams of a
+// capture at the given speed, so a client can be connected to a recorded
+// session. Written datagrams are discarded. Read returns io.EOF at the end of
+// the capture.
+func NewCaptureTransport(r *CaptureReader, speed float64) Transport {
+	return &captureTransport{r: r, speed: max(speed, 0), closed: make(chan struct{})}
+}
+
+type captureTransport struct {
+	r     *CaptureReader
+	speed float64
+
+	// mu serializes reads
+	mu      sync.Mutex
+	pending *CaptureRecord
+	started time.Time
+	first   time.Duration
+
+	deadlineMu sync.Mutex
+	deadline   time.Time
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *captureTransport) Read(b []byte) (n int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	for t.pending == nil {
+		var record CaptureRecord
+		record, err = t.r.Next()
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		if err != nil {
+			return
+		}
+		if record.Direction == CaptureInbound {
+			t.pending = &record
+		}
+	}
+	if t.started.IsZero() {
+		t.started = time.Now()
+		t.first = t.pending.Offset
+	}
+	if t.speed != ReplayAsFastAsPossible {
+		t.deadlineMu.Lock()
+		deadline := t.deadline
+		t.deadlineMu.Unlock()
+		due := t.started.Add(time.Duration(float64(t.pending.Offset-t.first) / t.speed))
+		deadlineExceeded := !deadline.IsZero() && deadline.Before(due)
+		wait := due
+		if deadlineExceeded {
+			wait = deadline
+		}
+		timer := time.NewTimer(time.Until(wait))
+		select {
+		case <-t.closed:
+			timer.Stop()
+			return 0, net.ErrClosed
+		case <-timer.C:
+		}
+		if deadlineExceeded {
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+	n = copy(b, t.pending.Data)
+	t.pending = nil
+	return
+}
+
+func (t *captureTransport) Write(b []byte) (n int, err error) {
+	select {
+	case <-t.closed:
+		return 0, net.ErrClosed
+	default:
+		return len(b), nil
+	}
+}
+
+func (t *captureTransport) SetReadDeadline(deadline time.Time) error {
+	t.deadlineMu.Lock()
+	defer t.deadlineMu.Unlock()
+	t.deadline = deadline
+	return nil
+}
+
+func (t *captureTransport) Close() (err error) {
+	err = net.ErrClosed
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		err = t.r.Close()
+	})
+	return
+}
@@ -0,0 +1,126 @@
+package AccTelemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// testCapture returns a capture of n realtime updates, one every 100ms of
+// session time.
+func testCapture(t *testing.T, n int) *bytes.Reader {
+	t.Helper()
+	var buffer bytes.Buffer
+	w, err := NewCaptureWriter(&buffer, CaptureHeader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		data, err := EncodeRealtimeUpdate(RealTimeUpdate{SessionTime: time.Duration(i) * 100 * time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = w.WriteRecord(CaptureRecord{Direction: CaptureInbound, Offset: time.Duration(i) * 100 * time.Millisecond, Data: data})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buffer.Bytes())
+}
+
+func newReplayClient(t *testing.T, opts ...Option) *AccUDPClient {
+	t.Helper()
+	opts = append([]Option{WithDialer(func() (Transport, error) { return nil, errors.New("replay only") })}, opts...)
+	c, err := NewAccUDPClient("", "replay", "", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestReplayPauseAndResume(t *testing.T) {
+	capture := testCapture(t, 5)
+	c := newReplayClient(t, WithoutChannels())
+	sub, err := c.Subscribe(EventFilter{Buffer: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewReplaySource(capture, capture.Size(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	err = s.Run(ctx)
+	cancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("paused Run error = %v, want DeadlineExceeded", err)
+	}
+	if err = s.Step(); err != nil {
+		t.Fatalf("Step error = %v", err)
+	}
+	s.SetSpeed(ReplayAsFastAsPossible)
+	if err = s.Run(context.Background()); err != nil {
+		t.Fatalf("resumed Run error = %v", err)
+	}
+	if err = s.Step(); err != io.EOF {
+		t.Fatalf("Step at the end error = %v, want io.EOF", err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Step(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Step after Close error = %v, want ErrClientClosed", err)
+	}
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	if count != 5 {
+		t.Errorf("delivered %d events, want 5", count)
+	}
+}
+
+func TestReplayCloseWhileRunning(t *testing.T) {
+	capture := testCapture(t, 20)
+	// nobody reads the channels, Run blocks on the second realtime update
+	c := newReplayClient(t, WithChannelBuffer(1))
+	s, err := NewReplaySource(capture, capture.Size(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetSpeed(ReplayAsFastAsPossible)
+	result := make(chan error, 1)
+	go func() {
+		result <- s.Run(context.Background())
+	}()
+	steps := make(chan error, 1)
+	go func() {
+		for {
+			if err := s.Step(); err != nil {
+				steps <- err
+				return
+			}
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-result:
+		if !errors.Is(err, ErrClientClosed) {
+			t.Errorf("Run error = %v, want ErrClientClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+	if err = <-steps; !errors.Is(err, ErrClientClosed) && err != io.EOF {
+		t.Errorf("Step error = %v, want ErrClientClosed", err)
+	}
+}
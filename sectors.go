@@ -0,0 +1,168 @@
+package AccTelemetry
+
+import (
+	"slices"
+	"time"
+)
+
+// lapSectors is the number of sectors ACC splits every lap into.
+const lapSectors = 3
+
+type SectorColor byte
+
+const (
+	// SectorColorYellow is slower than the personal best or set on an
+	// invalid lap.
+	SectorColorYellow SectorColor = iota
+	// SectorColorGreen is a personal best of the driver.
+	SectorColorGreen
+	// SectorColorPurple is the best of the session.
+	SectorColorPurple
+)
+
+func (c SectorColor) String() string {
+	switch c {
+	case SectorColorYellow:
+		return "yellow"
+	case SectorColorGreen:
+		return "green"
+	case SectorColorPurple:
+		return "purple"
+	}
+	return "unknown"
+}
+
+// SectorEvent is emitted when a car completes a sector.
+type SectorEvent struct {
+	CarIndex    uint16
+	DriverIndex uint16
+	// Lap is the number of the lap the sector belongs to, counting from 1.
+	Lap    int
+	Sector int
+	Time   time.Duration
+	Color  SectorColor
+	// IsInvalid is set for sectors of invalid laps, which never count as
+	// best sectors.
+	IsInvalid    bool
+	PersonalBest bool
+	SessionBest  bool
+}
+
+// SectorBests holds the best time of every sector, zero for sectors without
+// a time.
+type SectorBests struct {
+	Sectors []time.Duration
+	// TheoreticalBest is the sum of the best sectors, zero unless every
+	// sector has a time.
+	TheoreticalBest time.Duration
+}
+
+type driverKey struct {
+	carIndex    uint16
+	driverIndex uint16
+}
+
+func newSectorBests(sectors []time.Duration) SectorBests {
+	bests := SectorBests{Sectors: slices.Clone(sectors)}
+	for _, sector := range sectors {
+		if sector == 0 {
+			return SectorBests{Sectors: bests.Sectors}
+		}
+		bests.TheoreticalBest += sector
+	}
+	return bests
+}
+
+func (s *Session) OnSector(fn func(SectorEvent)) (remove func()) {
+	return s.sectorHandlers.add(fn)
+}
+
+// SectorBests returns the personal best sectors of a driver of a car.
+func (s *Session) SectorBests(carIndex uint16, driverIndex uint16) SectorBests {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return newSectorBests(s.driverBests[driverKey{carIndex, driverIndex}])
+}
+
+// SessionSectorBests returns the best sectors of the session.
+func (s *Session) SessionSectorBests() SectorBests {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return newSectorBests(s.sessionBests)
+}
+
+// trackSectors returns the sectors completed with update: the sectors of the
+// current lap as they appear in CurrentLap and the remaining ones of a lap
+// archived with the update. s.mu must be held.
+func (s *Session) trackSectors(car *CarState, update RealTimeCarUpdate, archived *ArchivedLap) (events []SectorEvent) {
+	tracker := s.tracker(update.CarIndex)
+	if tracker.sectors == nil {
+		tracker.sectors = make(map[int]int)
+	}
+	if archived != nil {
+		for i := tracker.sectors[archived.Number]; i < len(archived.Splits); i++ {
+			if sector, ok := archived.Sector(i); ok {
+				events = append(events, s.sectorCompleted(update.CarIndex, archived.DriverIndex, archived.Number, i, sector, archived.IsInvalid))
+			}
+		}
+		for lap := range tracker.sectors {
+			if lap <= archived.Number {
+				delete(tracker.sectors, lap)
+			}
+		}
+	}
+	lap := int(update.Laps) + 1
+	current := update.CurrentLap
+	i := tracker.sectors[lap]
+	for ; i < len(current.Splits); i++ {
+		sector, ok := current.Sector(i)
+		if !ok {
+			break
+		}
+		// sectors completed before the car was first seen are not new
+		if car.HasUpdate {
+			events = append(events, s.sectorCompleted(update.CarIndex, update.DriverIndex, lap, i, sector, current.IsInvalid))
+		}
+	}
+	tracker.sectors[lap] = i
+	return
+}
+
+// sectorCompleted classifies a sector and updates the bests. s.mu must be
+// held.
+func (s *Session) sectorCompleted(carIndex uint16, driverIndex uint16, lap int, sector int, sectorTime time.Duration, invalid bool) SectorEvent {
+	event := SectorEvent{
+		CarIndex:    carIndex,
+		DriverIndex: driverIndex,
+		Lap:         lap,
+		Sector:      sector,
+		Time:        sectorTime,
+		Color:       SectorColorYellow,
+		IsInvalid:   invalid,
+	}
+	if invalid {
+		return event
+	}
+	key := driverKey{carIndex, driverIndex}
+	// sized for the whole lap, so the theoretical best waits for every sector
+	size := max(sector+1, lapSectors)
+	personal := s.driverBests[key]
+	if len(personal) < size {
+		personal = append(personal, make([]time.Duration, size-len(personal))...)
+	}
+	if len(s.sessionBests) < size {
+		s.sessionBests = append(s.sessionBests, make([]time.Duration, size-len(s.sessionBests))...)
+	}
+	if personal[sector] == 0 || sectorTime <= personal[sector] {
+		personal[sector] = sectorTime
+		event.PersonalBest = true
+		event.Color = SectorColorGreen
+	}
+	if s.sessionBests[sector] == 0 || sectorTime <= s.sessionBests[sector] {
+		s.sessionBests[sector] = sectorTime
+		event.SessionBest = true
+		event.Color = SectorColorPurple
+	}
+	s.driverBests[key] = personal
+	return event
+}
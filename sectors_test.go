@@ -0,0 +1,104 @@
+package AccTelemetry
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestSession(t *testing.T) *Session {
+	t.Helper()
+	s := NewSession(newReplayClient(t, WithoutChannels()))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func timedLap(lapTimeMs int32, splits ...int32) LapInfo {
+	return LapInfo{LapTimeMs: lapTimeMs, Splits: splits, IsValidForBest: true, LapType: LapTypeRegular}
+}
+
+func TestSectorColors(t *testing.T) {
+	s := newTestSession(t)
+	var events []SectorEvent
+	s.OnSector(func(event SectorEvent) { events = append(events, event) })
+	carUpdate := func(carIndex uint16, laps uint16, current LapInfo, last LapInfo) {
+		s.handleCarUpdate(RealTimeCarUpdate{CarIndex: carIndex, Laps: laps, CarLocation: CarLocationTrack, CurrentLap: current, LastLap: last})
+	}
+	invalid := timedLap(0, 28000)
+	invalid.IsInvalid = true
+
+	carUpdate(1, 0, timedLap(0), LapInfo{})
+	carUpdate(2, 0, timedLap(0), LapInfo{})
+	carUpdate(1, 0, timedLap(0, 30000), LapInfo{})
+	carUpdate(1, 0, timedLap(0, 30000, 31000), LapInfo{})
+	carUpdate(2, 0, timedLap(0, 29000), LapInfo{})
+	carUpdate(2, 0, timedLap(0, 29000, 32000), LapInfo{})
+	carUpdate(1, 1, timedLap(0), timedLap(93000, 30000, 31000, 32000))
+	carUpdate(1, 1, invalid, timedLap(93000, 30000, 31000, 32000))
+	carUpdate(2, 1, timedLap(0), timedLap(94000, 29000, 32000, 33000))
+	carUpdate(2, 1, timedLap(0, 29500), timedLap(94000, 29000, 32000, 33000))
+
+	want := []struct {
+		carIndex uint16
+		lap      int
+		sector   int
+		color    SectorColor
+	}{
+		{1, 1, 0, SectorColorPurple},
+		{1, 1, 1, SectorColorPurple},
+		{2, 1, 0, SectorColorPurple},
+		{2, 1, 1, SectorColorGreen},
+		{1, 1, 2, SectorColorPurple},
+		{1, 2, 0, SectorColorYellow},
+		{2, 1, 2, SectorColorGreen},
+		{2, 2, 0, SectorColorYellow},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d sector events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.CarIndex != w.carIndex || e.Lap != w.lap || e.Sector != w.sector || e.Color != w.color {
+			t.Errorf("event %d = car %d lap %d sector %d %v, want car %d lap %d sector %d %v",
+				i, e.CarIndex, e.Lap, e.Sector, e.Color, w.carIndex, w.lap, w.sector, w.color)
+		}
+	}
+	if !events[5].IsInvalid || events[5].PersonalBest {
+		t.Errorf("sector of the invalid lap = %+v, want an invalid sector without personal best", events[5])
+	}
+}
+
+func TestTheoreticalBest(t *testing.T) {
+	s := newTestSession(t)
+	carUpdate := func(laps uint16, current LapInfo, last LapInfo) {
+		s.handleCarUpdate(RealTimeCarUpdate{CarIndex: 1, Laps: laps, CarLocation: CarLocationTrack, CurrentLap: current, LastLap: last})
+	}
+	carUpdate(0, timedLap(0), LapInfo{})
+	carUpdate(0, timedLap(0, 30000, 31000), LapInfo{})
+
+	partial := SectorBests{Sectors: []time.Duration{30 * time.Second, 31 * time.Second, 0}}
+	if got := s.SectorBests(1, 0); !reflect.DeepEqual(got, partial) {
+		t.Errorf("SectorBests after two sectors = %+v, want %+v", got, partial)
+	}
+	if got := s.SessionSectorBests(); !reflect.DeepEqual(got, partial) {
+		t.Errorf("SessionSectorBests after two sectors = %+v, want %+v", got, partial)
+	}
+
+	carUpdate(1, timedLap(0), timedLap(93000, 30000, 31000, 32000))
+	carUpdate(1, timedLap(0, 29000, 33000), timedLap(93000, 30000, 31000, 32000))
+	carUpdate(2, timedLap(0), timedLap(93500, 29000, 33000, 31500))
+
+	want := SectorBests{
+		Sectors:         []time.Duration{29 * time.Second, 31 * time.Second, 31500 * time.Millisecond},
+		TheoreticalBest: 91500 * time.Millisecond,
+	}
+	if got := s.SectorBests(1, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("SectorBests = %+v, want %+v", got, want)
+	}
+	if got := s.SessionSectorBests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SessionSectorBests = %+v, want %+v", got, want)
+	}
+	if got := s.SectorBests(1, 1); got.TheoreticalBest != 0 || got.Sectors != nil {
+		t.Errorf("SectorBests of a driver without laps = %+v, want zero", got)
+	}
+}
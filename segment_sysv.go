@@ -0,0 +1,43 @@
+//go:build linux && cgo
+
+package AccTelemetry
+
+import (
+	"io"
+
+	"github.com/ghetzel/shmtool/shm"
+)
+
+// OpenSysVSegment opens the System V shared memory segment id, e.g. one a
+// bridge fills with the pages of ACC running under Wine or Proton.
+func OpenSysVSegment(id int) (Segment, error) {
+	segment, err := shm.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	return &sysVSegment{segment: segment}, nil
+}
+
+type sysVSegment struct {
+	segment *shm.Segment
+}
+
+func (s *sysVSegment) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= s.segment.Size {
+		return 0, io.EOF
+	}
+	length := min(int64(len(p)), s.segment.Size-off)
+	data, err := s.segment.ReadChunk(length, off)
+	if err != nil {
+		return 0, err
+	}
+	n = copy(p, data)
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+func (s *sysVSegment) Close() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build windows
+
+package AccTelemetry
+
+import (
+	"io"
+	"syscall"
+	"unsafe"
+)
+
+const fileMapRead = 0x0004
+
+var procOpenFileMappingW = syscall.NewLazyDLL("kernel32.dll").NewProc("OpenFileMappingW")
+
+// OpenSharedMemory maps the named shared memory page of ACC, e.g.
+// OpenSharedMemory(PhysicsPageName, PhysicsPageSize). ACC must be running.
+func OpenSharedMemory(name string, size int) (Segment, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	handle, _, err := procOpenFileMappingW.Call(fileMapRead, 0, uintptr(unsafe.Pointer(namePtr)))
+	if handle == 0 {
+		return nil, err
+	}
+	addr, err := syscall.MapViewOfFile(syscall.Handle(handle), fileMapRead, 0, 0, uintptr(size))
+	if err != nil {
+		_ = syscall.CloseHandle(syscall.Handle(handle))
+		return nil, err
+	}
+	return &mappedSegment{
+		handle: syscall.Handle(handle),
+		addr:   addr,
+		data:   unsafe.Slice(*(**byte)(unsafe.Pointer(&addr)), size),
+	}, nil
+}
+
+type mappedSegment struct {
+	handle syscall.Handle
+	addr   uintptr
+	data   []byte
+}
+
+func (s *mappedSegment) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= int64(len(s.data)) {
+		return 0, io.EOF
+	}
+	n = copy(p, s.data[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+func (s *mappedSegment) Close() error {
+	err := syscall.UnmapViewOfFile(s.addr)
+	if closeErr := syscall.CloseHandle(s.handle); err == nil {
+		err = closeErr
+	}
+	return err
+}
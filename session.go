@@ -0,0 +1,229 @@
+package AccTelemetry
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Session joins the events of a client into the state of the current
+// session. It is safe for concurrent use.
+type Session struct {
+	mu        sync.RWMutex
+	track     TrackData
+	hasTrack  bool
+	update    RealTimeUpdate
+	hasUpdate bool
+	cars      map[uint16]*CarState
+	trackers  map[uint16]*lapTracker
+
+	sessionBests   []time.Duration
+	driverBests    map[driverKey][]time.Duration
+	sectorHandlers handlerList[SectorEvent]
+
+	removers []func()
+}
+
+// CarState is everything known about a car of the session.
+type CarState struct {
+	// Info is set once the entry list car arrived.
+	Info    CarInfo
+	HasInfo bool
+	// Update is the latest car update.
+	Update    RealTimeCarUpdate
+	HasUpdate bool
+	// Laps holds every lap completed in the session, oldest first.
+	Laps []ArchivedLap
+	// Stint is the current stint, 0 until the car left the pits.
+	Stint int
+}
+
+type Weather struct {
+	AmbientTemp byte
+	TrackTemp   byte
+	RainLevel   float32
+	Clouds      float32
+	Wetness     float32
+}
+
+// SessionSnapshot is a copy of the session state that is not modified by
+// later events.
+type SessionSnapshot struct {
+	Track    TrackData
+	HasTrack bool
+	// Update is the latest realtime update.
+	Update    RealTimeUpdate
+	HasUpdate bool
+	Phase     SessionPhase
+	Type      SessionType
+	Weather   Weather
+	Cars      map[uint16]CarState
+}
+
+// NewSession subscribes a new session model to the events of client.
+func NewSession(client *AccUDPClient) *Session {
+	s := &Session{
+		cars:        make(map[uint16]*CarState),
+		trackers:    make(map[uint16]*lapTracker),
+		driverBests: make(map[driverKey][]time.Duration),
+	}
+	s.removers = []func(){
+		client.OnTrackData(s.handleTrackData),
+		client.OnEntryList(s.handleEntryList),
+		client.OnEntryListCar(s.handleEntryListCar),
+		client.OnRealtimeUpdate(s.handleRealtimeUpdate),
+		client.OnCarUpdate(s.handleCarUpdate),
+		client.OnBroadcastEvent(s.handleBroadcastEvent),
+	}
+	return s
+}
+
+// Close stops updating the session.
+func (s *Session) Close() {
+	for _, remove := range s.removers {
+		remove()
+	}
+}
+
+func (s *Session) Snapshot() SessionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot := SessionSnapshot{
+		Track:     cloneTrackData(s.track),
+		HasTrack:  s.hasTrack,
+		Update:    cloneRealtimeUpdate(s.update),
+		HasUpdate: s.hasUpdate,
+		Phase:     s.update.Phase,
+		Type:      s.update.SessionType,
+		Weather: Weather{
+			AmbientTemp: s.update.AmbientTemp,
+			TrackTemp:   s.update.TrackTemp,
+			RainLevel:   s.update.RainLevel,
+			Clouds:      s.update.Clouds,
+			Wetness:     s.update.Wetness,
+		},
+		Cars: make(map[uint16]CarState, len(s.cars)),
+	}
+	for id, car := range s.cars {
+		snapshot.Cars[id] = car.clone()
+	}
+	return snapshot
+}
+
+// car returns the state of car id and creates it if needed. s.mu must be
+// held.
+func (s *Session) car(id uint16) *CarState {
+	car, ok := s.cars[id]
+	if !ok {
+		car = &CarState{}
+		s.cars[id] = car
+	}
+	return car
+}
+
+func (s *Session) handleTrackData(track TrackData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.track = cloneTrackData(track)
+	s.hasTrack = true
+}
+
+// handleEntryList drops the cars that left the session.
+func (s *Session) handleEntryList(entryList EntryList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.cars {
+		if !slices.Contains(entryList, id) {
+			delete(s.cars, id)
+			delete(s.trackers, id)
+		}
+	}
+	for _, id := range entryList {
+		s.car(id)
+	}
+}
+
+func (s *Session) handleEntryListCar(info CarInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	car := s.car(info.Id)
+	car.Info = cloneCarInfo(info)
+	car.HasInfo = true
+}
+
+func (s *Session) handleRealtimeUpdate(update RealTimeUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hasUpdate && s.update.SessionIndex != update.SessionIndex {
+		// a new session started, the entry list stays
+		for _, car := range s.cars {
+			car.Update = RealTimeCarUpdate{}
+			car.HasUpdate = false
+			car.Laps = nil
+			car.Stint = 0
+		}
+		clear(s.trackers)
+		clear(s.driverBests)
+		s.sessionBests = nil
+	}
+	s.update = cloneRealtimeUpdate(update)
+	s.hasUpdate = true
+}
+
+func (s *Session) handleCarUpdate(update RealTimeCarUpdate) {
+	s.mu.Lock()
+	car := s.car(update.CarIndex)
+	archived := s.trackLaps(car, update)
+	sectors := s.trackSectors(car, update, archived)
+	car.Update = cloneCarUpdate(update)
+	car.HasUpdate = true
+	s.mu.Unlock()
+	for _, event := range sectors {
+		s.sectorHandlers.call(event)
+	}
+}
+
+func (c *CarState) clone() CarState {
+	clone := *c
+	clone.Info = cloneCarInfo(c.Info)
+	clone.Update = cloneCarUpdate(c.Update)
+	clone.Laps = make([]ArchivedLap, len(c.Laps))
+	for i, lap := range c.Laps {
+		clone.Laps[i] = cloneArchivedLap(lap)
+	}
+	return clone
+}
+
+func cloneLap(lap LapInfo) LapInfo {
+	lap.Splits = slices.Clone(lap.Splits)
+	return lap
+}
+
+func cloneCarInfo(info CarInfo) CarInfo {
+	info.Drivers = slices.Clone(info.Drivers)
+	return info
+}
+
+func cloneCarUpdate(update RealTimeCarUpdate) RealTimeCarUpdate {
+	update.BestSessionLap = cloneLap(update.BestSessionLap)
+	update.LastLap = cloneLap(update.LastLap)
+	update.CurrentLap = cloneLap(update.CurrentLap)
+	return update
+}
+
+func cloneRealtimeUpdate(update RealTimeUpdate) RealTimeUpdate {
+	update.BestSessionLap = cloneLap(update.BestSessionLap)
+	return update
+}
+
+func cloneTrackData(track TrackData) TrackData {
+	track.HudPages = slices.Clone(track.HudPages)
+	if track.CameraSets != nil {
+		track.CameraSets = maps.Clone(track.CameraSets)
+		for name, cameras := range track.CameraSets {
+			track.CameraSets[name] = slices.Clone(cameras)
+		}
+	}
+	return track
+}
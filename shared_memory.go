@@ -0,0 +1,219 @@
+package AccTelemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+// ACC publishes its shared memory pages as named file mappings. The layouts
+// follow the ACC shared memory documentation (version 1.8) and are packed to
+// 4 bytes.
+const (
+	PhysicsPageName  = `Local\acpmf_physics`
+	GraphicsPageName = `Local\acpmf_graphics`
+	StaticPageName   = `Local\acpmf_static`
+)
+
+// Wheel indices of the per-wheel arrays of the shared memory pages.
+const (
+	WheelFrontLeft = iota
+	WheelFrontRight
+	WheelRearLeft
+	WheelRearRight
+)
+
+const (
+	DefaultPollInterval = 10 * time.Millisecond
+	DefaultStallTimeout = 500 * time.Millisecond
+)
+
+// Segment is a shared memory page or a dump of one. *os.File satisfies it,
+// so synthetic pages can be read from disk.
+type Segment interface {
+	ReadAt(p []byte, off int64) (n int, err error)
+	Close() error
+}
+
+// OpenFileSegment opens a dump of a shared memory page.
+func OpenFileSegment(path string) (Segment, error) {
+	return os.Open(path)
+}
+
+type Vector3 struct {
+	X float32
+	Y float32
+	Z float32
+}
+
+// wideString decodes a NUL terminated wchar_t array.
+func wideString(s []uint16) string {
+	for i, c := range s {
+		if c == 0 {
+			s = s[:i]
+			break
+		}
+	}
+	return string(utf16.Decode(s))
+}
+
+func milliseconds[T int32 | float32](ms T) time.Duration {
+	return time.Duration(float64(ms) * float64(time.Millisecond))
+}
+
+// readPage decodes the raw layout T from the start of segment.
+func readPage[T any](segment Segment, page string) (raw T, err error) {
+	data := make([]byte, binary.Size(raw))
+	n, err := segment.ReadAt(data, 0)
+	if n == len(data) {
+		err = nil
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return raw, fmt.Errorf("cannot read %s page: %w", page, err)
+	}
+	err = binary.Read(bytes.NewReader(data), binary.LittleEndian, &raw)
+	return
+}
+
+// Poller reads a shared memory page at a fixed interval. ACC increments the
+// packet id of a page whenever it writes a new frame, so only frames with a
+// new packet id are passed to the handlers.
+type Poller[T any] struct {
+	read         func() (T, error)
+	packetId     func(T) int32
+	interval     time.Duration
+	stallTimeout time.Duration
+	handlers     handlerList[T]
+	stall        handlerList[bool]
+
+	mu          sync.Mutex
+	stats       PollerStats
+	started     bool
+	lastId      int32
+	lastFresh   time.Time
+	windowStart time.Time
+	windowCount int
+}
+
+// PollerStats counts the frames seen by a Poller.
+type PollerStats struct {
+	// Frames is the number of fresh frames.
+	Frames uint64
+	// Duplicates is the number of polls that found no new frame.
+	Duplicates uint64
+	// Missed is the number of frames written by ACC between two polls.
+	Missed uint64
+	// Stalls is the number of times the page stopped changing, e.g. because
+	// the game was paused or in the menus.
+	Stalls  uint64
+	Stalled bool
+	// SampleRate is the number of fresh frames per second, measured over the
+	// last second.
+	SampleRate float64
+}
+
+func newPoller[T any](interval time.Duration, read func() (T, error), packetId func(T) int32) *Poller[T] {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return &Poller[T]{read: read, packetId: packetId, interval: interval, stallTimeout: DefaultStallTimeout}
+}
+
+// SetStallTimeout sets how long the packet id must stay the same until the
+// page is reported as stalled. It must be called before Run.
+func (p *Poller[T]) SetStallTimeout(timeout time.Duration) {
+	p.stallTimeout = timeout
+}
+
+func (p *Poller[T]) OnFrame(fn func(T)) (remove func()) {
+	return p.handlers.add(fn)
+}
+
+// OnStall is called with true when the page stalls and with false when fresh
+// frames arrive again.
+func (p *Poller[T]) OnStall(fn func(stalled bool)) (remove func()) {
+	return p.stall.add(fn)
+}
+
+func (p *Poller[T]) Stats() PollerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// Run polls until ctx is cancelled or the page cannot be read.
+func (p *Poller[T]) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		frame, err := p.read()
+		if err != nil {
+			return err
+		}
+		fresh, stalled, changed := p.observe(p.packetId(frame), time.Now())
+		if changed && !stalled {
+			p.stall.call(false)
+		}
+		if fresh {
+			p.handlers.call(frame)
+		}
+		if changed && stalled {
+			p.stall.call(true)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// observe updates the statistics with the packet id of a poll. It reports
+// whether the frame is fresh and whether the stall state changed.
+func (p *Poller[T]) observe(id int32, now time.Time) (fresh, stalled, changed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.windowStart.IsZero() {
+		p.windowStart = now
+	}
+	fresh = !p.started || id != p.lastId
+	switch {
+	case fresh:
+		// a smaller id means the game restarted the page
+		if p.started && id > p.lastId {
+			p.stats.Missed += uint64(id - p.lastId - 1)
+		}
+		p.started = true
+		p.lastId = id
+		p.lastFresh = now
+		p.stats.Frames++
+		p.windowCount++
+		if p.stats.Stalled {
+			p.stats.Stalled = false
+			changed = true
+		}
+	default:
+		p.stats.Duplicates++
+		if !p.stats.Stalled && now.Sub(p.lastFresh) >= p.stallTimeout {
+			p.stats.Stalled = true
+			p.stats.Stalls++
+			changed = true
+		}
+	}
+	if elapsed := now.Sub(p.windowStart); elapsed >= time.Second {
+		p.stats.SampleRate = float64(p.windowCount) / elapsed.Seconds()
+		p.windowStart = now
+		p.windowCount = 0
+	}
+	return fresh, p.stats.Stalled, changed
+}
@@ -0,0 +1,193 @@
+package AccTelemetry
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// page is a synthetic dump of a shared memory page.
+type page []byte
+
+func (p page) int32(offset int, v int32) {
+	binary.LittleEndian.PutUint32(p[offset:], uint32(v))
+}
+
+func (p page) float32(offset int, v float32) {
+	binary.LittleEndian.PutUint32(p[offset:], math.Float32bits(v))
+}
+
+func (p page) string(offset int, s string) {
+	for i, c := range utf16.Encode([]rune(s)) {
+		binary.LittleEndian.PutUint16(p[offset+2*i:], c)
+	}
+}
+
+// open writes the page to a file and opens it as a segment.
+func (p page) open(t *testing.T) Segment {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "page")
+	if err := os.WriteFile(path, p, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	segment, err := OpenFileSegment(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = segment.Close() })
+	return segment
+}
+
+func TestPageSizes(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		raw  any
+		size int
+	}{
+		{"physics", rawPhysics{}, PhysicsPageSize},
+		{"graphics", rawGraphics{}, GraphicsPageSize},
+		{"static", rawStatic{}, StaticPageSize},
+	} {
+		if size := binary.Size(test.raw); size != test.size {
+			t.Errorf("size of the %s page = %d, want %d", test.name, size, test.size)
+		}
+	}
+}
+
+// The offsets below are taken from the ACC shared memory documentation.
+
+func TestReadPhysics(t *testing.T) {
+	p := make(page, PhysicsPageSize)
+	p.int32(0, 42)
+	p.float32(4, 0.75)
+	p.int32(16, 4)
+	p.float32(28, 212.5)
+	p.float32(96, 27.5)
+	p.int32(248, 1)
+	p.float32(712, 88)
+	p.int32(780, 1)
+	physics, err := ReadPhysics(p.open(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if physics.PacketId != 42 || physics.Throttle != 0.75 || physics.Gear != 3 || physics.SpeedKmh != 212.5 {
+		t.Errorf("physics = %+v, want packet 42, throttle 0.75, third gear and 212.5km/h", physics)
+	}
+	if physics.TyrePressure[WheelRearLeft] != 27.5 || !physics.PitLimiterOn || physics.WaterTemp != 88 || !physics.IsEngineRunning {
+		t.Errorf("physics = %+v, want 27.5psi rear left, pit limiter, 88°C water and a running engine", physics)
+	}
+}
+
+func TestReadGraphics(t *testing.T) {
+	p := make(page, GraphicsPageSize)
+	p.int32(0, 7)
+	p.int32(4, int32(GraphicsStatusLive))
+	p.int32(8, 2)
+	p.int32(132, 5)
+	p.int32(136, 2)
+	p.int32(140, 61500)
+	p.string(176, "dry_compound")
+	p.int32(252, 1)
+	p.float32(256, 10)
+	p.float32(260, 20)
+	p.float32(264, 30)
+	p.int32(976, 1001)
+	p.int32(1216, 1001)
+	p.int32(1224, int32(FlagTypeYellow))
+	p.int32(1580, 1250)
+	graphics, err := ReadGraphics(p.open(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graphics.PacketId != 7 || graphics.Status != GraphicsStatusLive || graphics.SessionType != SessionTypeRace {
+		t.Errorf("graphics = %+v, want packet 7 of a live race", graphics)
+	}
+	if graphics.CompletedLaps != 5 || graphics.Position != 2 || graphics.CurrentLapTime != 61500*time.Millisecond {
+		t.Errorf("graphics = %+v, want P2 after 5 laps at 1:01.500", graphics)
+	}
+	if graphics.TyreCompound != "dry_compound" || graphics.Flag != FlagTypeYellow || graphics.GapAhead != 1250*time.Millisecond {
+		t.Errorf("graphics = %+v, want dry tyres, a yellow flag and 1.25s to the car ahead", graphics)
+	}
+	want := GraphicsCar{CarId: 1001, Coordinates: Vector3{X: 10, Y: 20, Z: 30}}
+	if graphics.PlayerCarId != 1001 || len(graphics.Cars) != 1 || graphics.Cars[0] != want {
+		t.Errorf("cars = %+v of player %d, want %+v", graphics.Cars, graphics.PlayerCarId, want)
+	}
+}
+
+func TestReadStatic(t *testing.T) {
+	p := make(page, StaticPageSize)
+	p.string(0, "1.8")
+	p.int32(64, 30)
+	p.string(68, "ferrari_296_gt3")
+	p.string(134, "monza_2020")
+	p.string(200, "Max")
+	p.int32(400, 3)
+	p.int32(412, 8000)
+	p.int32(676, 1200000)
+	p.int32(680, 1800000)
+	p.int32(684, 1)
+	p.string(688, "DHE")
+	p.string(754, "WH")
+	static, err := ReadStatic(p.open(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if static.SmVersion != "1.8" || static.NumCars != 30 || static.PlayerName != "Max" || static.SectorCount != 3 || static.MaxRpm != 8000 {
+		t.Errorf("static = %+v, want version 1.8, 30 cars, player Max, 3 sectors and 8000rpm", static)
+	}
+	if static.CarModel.OrElse(CarModelPorsche) != CarModelFerrari296 || static.TrackId.OrElse(TrackIdSpa) != TrackIdMonza {
+		t.Errorf("static = %+v, want a Ferrari 296 at Monza", static)
+	}
+	if static.PitWindowStart != 20*time.Minute || static.PitWindowEnd != 30*time.Minute || !static.IsOnline {
+		t.Errorf("static = %+v, want an online session with a pit window from 20 to 30 minutes", static)
+	}
+	if static.DryTyresName != "DHE" || static.WetTyresName != "WH" {
+		t.Errorf("tyres = %q/%q, want DHE/WH", static.DryTyresName, static.WetTyresName)
+	}
+}
+
+func TestReadShortPage(t *testing.T) {
+	p := make(page, PhysicsPageSize-4)
+	if _, err := ReadPhysics(p.open(t)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadPhysics error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestPollerObserve(t *testing.T) {
+	p := newPoller(DefaultPollInterval, nil, func(id int32) int32 { return id })
+	start := time.Now()
+	for i, step := range []struct {
+		id      int32
+		at      time.Duration
+		fresh   bool
+		stalled bool
+		changed bool
+	}{
+		{1, 0, true, false, false},
+		{1, 10 * time.Millisecond, false, false, false},
+		// frames 2 and 3 were missed
+		{4, 20 * time.Millisecond, true, false, false},
+		{4, 519 * time.Millisecond, false, false, false},
+		{4, 520 * time.Millisecond, false, true, true},
+		{4, 600 * time.Millisecond, false, true, false},
+		{5, 700 * time.Millisecond, true, false, true},
+		// the game restarted the page
+		{2, time.Second, true, false, false},
+	} {
+		fresh, stalled, changed := p.observe(step.id, start.Add(step.at))
+		if fresh != step.fresh || stalled != step.stalled || changed != step.changed {
+			t.Errorf("poll %d of %d = fresh %t stalled %t changed %t, want %t %t %t",
+				i, step.id, fresh, stalled, changed, step.fresh, step.stalled, step.changed)
+		}
+	}
+	want := PollerStats{Frames: 4, Duplicates: 4, Missed: 2, Stalls: 1, SampleRate: 4}
+	if stats := p.Stats(); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,235 @@
+package AccTelemetry
+
+import (
+	"github.com/Soemii/goptional"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rawStatic mirrors SPageFileStatic. wchar_t arrays of odd length are
+// followed by 2 bytes of padding when a 4 byte field follows.
+type rawStatic struct {
+	SmVersion                [15]uint16
+	AcVersion                [15]uint16
+	NumberOfSessions         int32
+	NumCars                  int32
+	CarModel                 [33]uint16
+	Track                    [33]uint16
+	PlayerName               [33]uint16
+	PlayerSurname            [33]uint16
+	PlayerNick               [33]uint16
+	_                        [2]byte
+	SectorCount              int32
+	MaxTorque                float32
+	MaxPower                 float32
+	MaxRpm                   int32
+	MaxFuel                  float32
+	SuspensionMaxTravel      [4]float32
+	TyreRadius               [4]float32
+	MaxTurboBoost            float32
+	Deprecated1              float32
+	Deprecated2              float32
+	PenaltiesEnabled         int32
+	AidFuelRate              float32
+	AidTireRate              float32
+	AidMechanicalDamage      float32
+	AidAllowTyreBlankets     float32
+	AidStability             float32
+	AidAutoClutch            int32
+	AidAutoBlip              int32
+	HasDRS                   int32
+	HasERS                   int32
+	HasKERS                  int32
+	KersMaxJ                 float32
+	EngineBrakeSettingsCount int32
+	ErsPowerControllerCount  int32
+	TrackSplineLength        float32
+	TrackConfiguration       [33]uint16
+	_                        [2]byte
+	ErsMaxJ                  float32
+	IsTimedRace              int32
+	HasExtraLap              int32
+	CarSkin                  [33]uint16
+	_                        [2]byte
+	ReversedGridPositions    int32
+	PitWindowStart           int32
+	PitWindowEnd             int32
+	IsOnline                 int32
+	DryTyresName             [33]uint16
+	WetTyresName             [33]uint16
+}
+
+// StaticPageSize is the size of the static page in bytes.
+const StaticPageSize = 820
+
+// Static is the static page, written once when a session is loaded.
+type Static struct {
+	SmVersion        string
+	AcVersion        string
+	NumberOfSessions int32
+	NumCars          int32
+	CarModelName     string
+	// CarModel is empty if CarModelName is not known.
+	CarModel  goptional.Optional[CarModel]
+	TrackName string
+	// TrackId is empty if TrackName is not known.
+	TrackId       goptional.Optional[TrackId]
+	PlayerName    string
+	PlayerSurname string
+	PlayerNick    string
+	SectorCount   int32
+	MaxRpm        int32
+	MaxFuel       float32
+	DryTyresName  string
+	WetTyresName  string
+	// PitWindowStart and PitWindowEnd are the pit window in session time.
+	PitWindowStart time.Duration
+	PitWindowEnd   time.Duration
+
+	PenaltiesEnabled     bool
+	AidFuelRate          float32
+	AidTireRate          float32
+	AidMechanicalDamage  float32
+	AidAllowTyreBlankets bool
+	AidStability         float32
+	AidAutoClutch        bool
+	AidAutoBlip          bool
+	IsOnline             bool
+}
+
+// ReadStatic reads the static page from segment.
+func ReadStatic(segment Segment) (static Static, err error) {
+	raw, err := readPage[rawStatic](segment, "static")
+	if err != nil {
+		return
+	}
+	return raw.static(), nil
+}
+
+func (r *rawStatic) static() Static {
+	static := Static{
+		SmVersion:            wideString(r.SmVersion[:]),
+		AcVersion:            wideString(r.AcVersion[:]),
+		NumberOfSessions:     r.NumberOfSessions,
+		NumCars:              r.NumCars,
+		CarModelName:         wideString(r.CarModel[:]),
+		CarModel:             goptional.NewEmptyOptional[CarModel](),
+		TrackName:            wideString(r.Track[:]),
+		TrackId:              goptional.NewEmptyOptional[TrackId](),
+		PlayerName:           wideString(r.PlayerName[:]),
+		PlayerSurname:        wideString(r.PlayerSurname[:]),
+		PlayerNick:           wideString(r.PlayerNick[:]),
+		SectorCount:          r.SectorCount,
+		MaxRpm:               r.MaxRpm,
+		MaxFuel:              r.MaxFuel,
+		DryTyresName:         wideString(r.DryTyresName[:]),
+		WetTyresName:         wideString(r.WetTyresName[:]),
+		PitWindowStart:       milliseconds(r.PitWindowStart),
+		PitWindowEnd:         milliseconds(r.PitWindowEnd),
+		PenaltiesEnabled:     r.PenaltiesEnabled != 0,
+		AidFuelRate:          r.AidFuelRate,
+		AidTireRate:          r.AidTireRate,
+		AidMechanicalDamage:  r.AidMechanicalDamage,
+		AidAllowTyreBlankets: r.AidAllowTyreBlankets != 0,
+		AidStability:         r.AidStability,
+		AidAutoClutch:        r.AidAutoClutch != 0,
+		AidAutoBlip:          r.AidAutoBlip != 0,
+		IsOnline:             r.IsOnline != 0,
+	}
+	if model, ok := ParseCarModel(static.CarModelName); ok {
+		static.CarModel = goptional.NewOptional(model)
+	}
+	if track, ok := ParseTrackId(static.TrackName); ok {
+		static.TrackId = goptional.NewOptional(track)
+	}
+	return static
+}
+
+var carModelNames = map[string]CarModel{
+	"porsche_991_gt3_r":            CarModelPorsche991GT3R,
+	"mercedes_amg_gt3":             CarModelMercedes,
+	"ferrari_488_gt3":              CarModelFerrari,
+	"audi_r8_lms":                  CarModelAudiR8LMS,
+	"lamborghini_huracan_gt3":      CarModelLamborghiniHuracan,
+	"mclaren_650s_gt3":             CarModelMcLaren650S,
+	"nissan_gt_r_gt3_2018":         CarModelNissanGTR2018,
+	"bmw_m6_gt3":                   CarModelBMWM6,
+	"bentley_continental_gt3_2018": CarModelBentley2018,
+	"porsche_991ii_gt3_cup":        CarModelPorsche991IICup,
+	"nissan_gt_r_gt3_2017":         CarModelNissanGTR2017,
+	"bentley_continental_gt3_2016": CarModelBentley2016,
+	"amr_v12_vantage_gt3":          CarModelAstonMartinV12,
+	"lamborghini_gallardo_rex":     CarModelLamborghiniGallardoREX,
+	"jaguar_g3":                    CarModelJaguarG3,
+	"lexus_rc_f_gt3":               CarModelLexus,
+	"lamborghini_huracan_gt3_evo":  CarModelLamborghini,
+	"honda_nsx_gt3":                CarModelHondaNSX,
+	"lamborghini_huracan_st":       CarModelLamborghiniHuracanST,
+	"audi_r8_lms_evo":              CarModelAudi,
+	"amr_v8_vantage_gt3":           CarModelAstonMartin,
+	"honda_nsx_gt3_evo":            CarModelHondaNSXEvo,
+	"mclaren_720s_gt3":             CarModelMcLaren720S,
+	"porsche_991ii_gt3_r":          CarModelPorsche,
+	"ferrari_488_gt3_evo":          CarModelFerrariEvo,
+	"mercedes_amg_gt3_evo":         CarModelMercedesEvo,
+	"ferrari_488_challenge_evo":    CarModelFerrariChallengeEvo,
+	"bmw_m2_cs_racing":             CarModelBMWM2CS,
+	"porsche_992_gt3_cup":          CarModelPorsche992Cup,
+	"lamborghini_huracan_st_evo2":  CarModelLamborghiniHuracanSTEvo,
+	"bmw_m4_gt3":                   CarModelBMWM4,
+	"audi_r8_lms_evo_ii":           CarModelAudiEvo2,
+	"ferrari_296_gt3":              CarModelFerrari296,
+	"lamborghini_huracan_gt3_evo2": CarModelLamborghiniEvo2,
+	"porsche_992_gt3_r":            CarModelPorsche992GT3R,
+	"mclaren_720s_gt3_evo":         CarModelMcLaren720SEvo,
+	"ford_mustang_gt3":             CarModelFordMustang,
+	"alpine_a110_gt4":              CarModelAlpineGT4,
+	"amr_v8_vantage_gt4":           CarModelAstonMartinGT4,
+	"audi_r8_gt4":                  CarModelAudiGT4,
+	"bmw_m4_gt4":                   CarModelBMWGT4,
+	"chevrolet_camaro_gt4r":        CarModelChevroletGT4,
+	"ginetta_g55_gt4":              CarModelGinettaGT4,
+	"ktm_xbow_gt4":                 CarModelKTMGT4,
+	"maserati_mc_gt4":              CarModelMaseratiGT4,
+	"mclaren_570s_gt4":             CarModelMcLarenGT4,
+	"mercedes_amg_gt4":             CarModelMercedesGT4,
+	"porsche_718_cayman_gt4_mr":    CarModelPorscheGT4,
+}
+
+var trackNames = map[string]TrackId{
+	"brands_hatch":   TrackIdBrandsHatch,
+	"spa":            TrackIdSpa,
+	"monza":          TrackIdMonza,
+	"misano":         TrackIdMisano,
+	"paul_ricard":    TrackIdPaulRicard,
+	"silverstone":    TrackIdSilverstone,
+	"hungaroring":    TrackIdHungaroring,
+	"nurburgring":    TrackIdNurburgring,
+	"barcelona":      TrackIdBarcelona,
+	"zolder":         TrackIdZolder,
+	"zandvoort":      TrackIdZandvoort,
+	"mount_panorama": TrackIdBathurst,
+	"laguna_seca":    TrackIdLagunaSeca,
+	"suzuka":         TrackIdSuzuka,
+}
+
+// ParseCarModel maps the car model name of the static page, e.g.
+// "porsche_992_gt3_r", onto CarModel.
+func ParseCarModel(name string) (CarModel, bool) {
+	model, ok := carModelNames[strings.ToLower(name)]
+	return model, ok
+}
+
+// ParseTrackId maps the track name of the static page, e.g. "monza" or
+// "monza_2020", onto TrackId.
+func ParseTrackId(name string) (TrackId, bool) {
+	name = strings.ToLower(name)
+	if i := strings.LastIndexByte(name, '_'); i >= 0 && len(name)-i == 5 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			name = name[:i]
+		}
+	}
+	track, ok := trackNames[name]
+	return track, ok
+}
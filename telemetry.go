@@ -0,0 +1,119 @@
+package AccTelemetry
+
+import "sync"
+
+// DefaultAlignmentTolerance is the largest distance, in laps, between the
+// shared memory position and a car update that are merged.
+const DefaultAlignmentTolerance = 0.01
+
+const telemetryHistory = 16
+
+// PlayerTelemetry joins the shared memory pages of the player's car with the
+// broadcasting car update of the same position on track.
+type PlayerTelemetry struct {
+	CarIndex uint16
+	// Laps and SplinePosition are taken from the graphics page.
+	Laps           int32
+	SplinePosition float32
+	Physics        Physics
+	Graphics       Graphics
+	// Car is the car update closest to the position of the frame. HasCar is
+	// false when none is within the alignment tolerance.
+	Car    RealTimeCarUpdate
+	HasCar bool
+}
+
+// TelemetryMerger emits a PlayerTelemetry for every physics frame. Car updates
+// are matched by lap and spline position, because the broadcasting updates
+// arrive far less often and with a different delay than shared memory frames.
+type TelemetryMerger struct {
+	tolerance float32
+	handlers  handlerList[PlayerTelemetry]
+	removers  []func()
+
+	mu          sync.Mutex
+	graphics    Graphics
+	hasGraphics bool
+	history     []RealTimeCarUpdate
+}
+
+func NewTelemetryMerger(client *AccUDPClient, physics *Poller[Physics], graphics *Poller[Graphics]) *TelemetryMerger {
+	m := &TelemetryMerger{tolerance: DefaultAlignmentTolerance}
+	m.removers = []func(){
+		client.OnCarUpdate(m.carUpdate),
+		graphics.OnFrame(m.graphicsFrame),
+		physics.OnFrame(m.physicsFrame),
+	}
+	return m
+}
+
+// SetTolerance sets the largest distance in laps between a frame and the car
+// update merged into it.
+func (m *TelemetryMerger) SetTolerance(laps float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tolerance = laps
+}
+
+func (m *TelemetryMerger) OnTelemetry(fn func(PlayerTelemetry)) (remove func()) {
+	return m.handlers.add(fn)
+}
+
+// Close detaches the merger from its sources.
+func (m *TelemetryMerger) Close() {
+	for _, remove := range m.removers {
+		remove()
+	}
+}
+
+func (m *TelemetryMerger) graphicsFrame(graphics Graphics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hasGraphics && m.graphics.PlayerCarId != graphics.PlayerCarId {
+		m.history = m.history[:0]
+	}
+	m.graphics = graphics
+	m.hasGraphics = true
+}
+
+func (m *TelemetryMerger) carUpdate(update RealTimeCarUpdate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasGraphics || int32(update.CarIndex) != m.graphics.PlayerCarId {
+		return
+	}
+	if len(m.history) == telemetryHistory {
+		m.history = append(m.history[:0], m.history[1:]...)
+	}
+	m.history = append(m.history, update)
+}
+
+func (m *TelemetryMerger) physicsFrame(physics Physics) {
+	m.mu.Lock()
+	if !m.hasGraphics {
+		m.mu.Unlock()
+		return
+	}
+	telemetry := PlayerTelemetry{
+		CarIndex:       uint16(m.graphics.PlayerCarId),
+		Laps:           m.graphics.CompletedLaps,
+		SplinePosition: m.graphics.NormalizedCarPosition,
+		Physics:        physics,
+		Graphics:       m.graphics,
+	}
+	position := float32(telemetry.Laps) + telemetry.SplinePosition
+	best := m.tolerance
+	for _, update := range m.history {
+		distance := position - (float32(update.Laps) + update.SplinePosition)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= best {
+			best = distance
+			telemetry.Car = update
+			telemetry.HasCar = true
+		}
+	}
+	m.mu.Unlock()
+	m.handlers.call(telemetry)
+}
@@ -0,0 +1,207 @@
+package AccTelemetry
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Transport carries the datagrams between the client and ACC. *net.UDPConn
+// satisfies it.
+//
+// Read returns one datagram per call. When the read deadline passes it must
+// return an error for which errors.Is(err, os.ErrDeadlineExceeded) holds, and
+// after Close it must return net.ErrClosed. io.EOF ends the listener.
+type Transport interface {
+	Read(b []byte) (n int, err error)
+	Write(b []byte) (n int, err error)
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// Dialer opens the transport of a connection. It is called on every Connect.
+type Dialer func() (Transport, error)
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, os.ErrDeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (c *AccUDPClient) dialUDP() (Transport, error) {
+	conn, err := net.DialUDP("udp", c.localAddr, c.remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// NewPipe returns the two ends of an in-memory packet connection. Datagrams
+// written to one end are read from the other. Closing an end does not close
+// its peer, just like a UDP socket whose counterpart went away.
+func NewPipe() (Transport, Transport) {
+	a := make(chan []byte, DefaultChannelBuffer)
+	b := make(chan []byte, DefaultChannelBuffer)
+	aClosed := make(chan struct{})
+	bClosed := make(chan struct{})
+	return &pipeEnd{in: a, out: b, closed: aClosed, peerClosed: bClosed},
+		&pipeEnd{in: b, out: a, closed: bClosed, peerClosed: aClosed}
+}
+
+type pipeEnd struct {
+	in         <-chan []byte
+	out        chan<- []byte
+	closed     chan struct{}
+	peerClosed <-chan struct{}
+	closeOnce  sync.Once
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (p *pipeEnd) Read(b []byte) (n int, err error) {
+	p.mu.Lock()
+	deadline := p.deadline
+	p.mu.Unlock()
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-p.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	select {
+	case <-p.closed:
+		return 0, net.ErrClosed
+	case <-timeout:
+		return 0, os.ErrDeadlineExceeded
+	case datagram := <-p.in:
+		return copy(b, datagram), nil
+	}
+}
+
+// Write blocks while the peer has DefaultChannelBuffer unread datagrams.
+// Datagrams written after the peer was closed are discarded.
+func (p *pipeEnd) Write(b []byte) (n int, err error) {
+	datagram := append([]byte(nil), b...)
+	select {
+	case <-p.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	select {
+	case <-p.closed:
+		return 0, net.ErrClosed
+	case <-p.peerClosed:
+		return len(b), nil
+	case p.out <- datagram:
+		return len(b), nil
+	}
+}
+
+// SetReadDeadline applies to subsequent reads only.
+func (p *pipeEnd) SetReadDeadline(t time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deadline = t
+	return nil
+}
+
+func (p *pipeEnd) Close() error {
+	err := net.ErrClosed
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		err = nil
+	})
+	return err
+}
+
+// NewStreamTransport frames datagrams on a stream connection such as TCP, so
+// the client can be used through a relay. Every datagram is prefixed with its
+// length as little-endian uint32. Both ends of the relay wrap their
+// connection with NewStreamTransport.
+func NewStreamTransport(conn net.Conn) Transport {
+	return &streamTransport{conn: conn}
+}
+
+type streamTransport struct {
+	conn net.Conn
+
+	writeMu sync.Mutex
+
+	// the frame being read survives read timeouts
+	header  [4]byte
+	headerN int
+	frame   []byte
+	frameN  int
+	// err is returned by every read after the framing broke
+	err error
+}
+
+func (s *streamTransport) Read(b []byte) (n int, err error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	for s.headerN < len(s.header) {
+		n, err = s.conn.Read(s.header[s.headerN:])
+		s.headerN += n
+		if err != nil {
+			if errors.Is(err, io.EOF) && s.headerN > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+	if s.frame == nil {
+		length := binary.LittleEndian.Uint32(s.header[:])
+		if length > ReadBufferSize {
+			// the stream cannot be resynchronised after a bad header
+			s.headerN = 0
+			_ = s.conn.Close()
+			s.err = fmt.Errorf("stream frame of %d bytes exceeds read buffer: %w", length, net.ErrClosed)
+			return 0, s.err
+		}
+		s.frame = make([]byte, length)
+	}
+	for s.frameN < len(s.frame) {
+		n, err = s.conn.Read(s.frame[s.frameN:])
+		s.frameN += n
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+	n = copy(b, s.frame)
+	s.headerN, s.frame, s.frameN = 0, nil, 0
+	return n, nil
+}
+
+func (s *streamTransport) Write(b []byte) (n int, err error) {
+	frame := make([]byte, 4+len(b))
+	binary.LittleEndian.PutUint32(frame, uint32(len(b)))
+	copy(frame[4:], b)
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err = s.conn.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (s *streamTransport) SetReadDeadline(t time.Time) error {
+	return s.conn.SetReadDeadline(t)
+}
+
+func (s *streamTransport) Close() error {
+	return s.conn.Close()
+}
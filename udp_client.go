@@ -2,34 +2,145 @@ package AccTelemetry
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"github.com/Soemii/goptional"
-	"log"
+	"io"
+	"log/slog"
+	"math"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const ReadBufferSize = 32 * 1024
 
-func NewAccUDPClient(address string, displayName string, connectionPassword string, msRealtimeUpdateInterval int32, commandPassword string, timeoutMs int32) *AccUDPClient {
-	return &AccUDPClient{
-		address:                  address,
-		displayName:              displayName,
-		connectionPassword:       connectionPassword,
-		msRealtimeUpdateInterval: msRealtimeUpdateInterval,
-		commandPassword:          connectionPassword,
-		timeoutMs:                timeoutMs,
+var (
+	ErrRegistrationTimeout = errors.New("no registration result received from ACC")
+	ErrReadOnly            = errors.New("connection is read-only, the command password was rejected")
+	ErrNotConnected        = errors.New("client is not connected")
+	ErrClientClosed        = errors.New("client is closed, its event channels were closed")
+)
+
+// RegistrationError is returned when ACC rejects the registration, e.g. because
+// of a wrong connection password or an unsupported protocol version.
+type RegistrationError struct {
+	Message string
+}
+
+func (e *RegistrationError) Error() string {
+	return "registration rejected by ACC: " + e.Message
+}
+
+// NewAccUDPClient creates a client for the ACC broadcasting interface at
+// address. All event channels are created with the configured buffer size.
+// The address may be empty when a transport is set with WithDialer or
+// WithTransport.
+func NewAccUDPClient(address string, displayName string, connectionPassword string, opts ...Option) (*AccUDPClient, error) {
+	c := &AccUDPClient{
+		address:            address,
+		displayName:        displayName,
+		connectionPassword: connectionPassword,
+		updateInterval:     DefaultUpdateInterval,
+		timeOutDuration:    DefaultTimeout,
+		channelBuffer:      DefaultChannelBuffer,
+		entryListRefresh:   DefaultEntryListRefresh,
+		logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		// the connection loop emits these itself and must not wait for a
+		// consumer, only the latest state matters
+		policies: [streamCount]BackpressurePolicy{
+			StreamConnectionStates: BackpressureDropOldest,
+			StreamRegistrations:    BackpressureDropOldest,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	c.msRealtimeUpdateInterval = int32(c.updateInterval / time.Millisecond)
+	c.initStreams()
+	for _, h := range c.initialHandlers {
+		c.AddHandler(h)
 	}
+	if c.withoutChannels {
+		return c, nil
+	}
+	c.ErrChannel = make(chan error, c.channelBuffer)
+	c.BroadCastEventChannel = make(chan BroadCastEvent, c.channelBuffer)
+	c.TrackDataEventChannel = make(chan TrackData, c.channelBuffer)
+	c.EntryListCarEventChannel = make(chan CarInfo, c.channelBuffer)
+	c.EntryListEventChannel = make(chan EntryList, c.channelBuffer)
+	c.RealtimeUpdateEventChannel = make(chan RealTimeUpdate, c.channelBuffer)
+	c.RealtimeCarUpdateEventChannel = make(chan RealTimeCarUpdate, c.channelBuffer)
+	c.ConnectionStateEventChannel = make(chan ConnectionState, c.channelBuffer)
+	c.RegistrationEventChannel = make(chan RegistrationResult, c.channelBuffer)
+	return c, nil
+}
+
+func (c *AccUDPClient) validate() (err error) {
+	switch {
+	case c.address == "" && c.dialer == nil:
+		return errors.New("address must not be empty")
+	case c.displayName == "":
+		return errors.New("display name must not be empty")
+	case c.updateInterval < time.Millisecond || c.updateInterval/time.Millisecond > math.MaxInt32:
+		return errors.New("update interval must be between 1ms and MaxInt32 milliseconds")
+	case c.timeOutDuration <= 0:
+		return errors.New("timeout must be positive")
+	case c.channelBuffer < 0:
+		return errors.New("channel buffer must not be negative")
+	case c.entryListRefresh < 0:
+		return errors.New("entry list refresh must not be negative")
+	case c.logger == nil:
+		return errors.New("logger must not be nil")
+	}
+	for _, policy := range c.policies {
+		if policy > BackpressureCoalesce {
+			return errors.New("unknown backpressure policy")
+		}
+	}
+	if c.dialer != nil {
+		return
+	}
+	c.dialer = c.dialUDP
+	c.remoteAddr, err = net.ResolveUDPAddr("udp", c.address)
+	if err != nil {
+		return
+	}
+	if c.localAddress != "" {
+		c.localAddr, err = net.ResolveUDPAddr("udp", c.localAddress)
+	}
+	return
 }
 
 type AccUDPClient struct {
-	conn *net.UDPConn
+	conn   Transport
+	dialer Dialer
 
 	timeOutDuration time.Duration
 
-	connectionId int32
+	connectionId atomic.Int32
+	readOnly     atomic.Bool
+	registration RegistrationResult
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	err      error
+	closed   bool
 
-	listening bool
+	state        ConnectionState
+	lastUpdate   time.Time
+	retryDelay   time.Duration
+	nextRetry    time.Time
+	reconnecting bool
+
+	knownCars            map[uint16]int
+	entryListReceived    time.Time
+	lastEntryListRequest time.Time
 
 	ErrChannel                    chan error
 	BroadCastEventChannel         chan BroadCastEvent
@@ -38,143 +149,399 @@ type AccUDPClient struct {
 	EntryListEventChannel         chan EntryList
 	RealtimeUpdateEventChannel    chan RealTimeUpdate
 	RealtimeCarUpdateEventChannel chan RealTimeCarUpdate
+	ConnectionStateEventChannel   chan ConnectionState
+	RegistrationEventChannel      chan RegistrationResult
+
+	errStream               eventStream[error]
+	broadcastEventStream    eventStream[BroadCastEvent]
+	trackDataStream         eventStream[TrackData]
+	entryListCarStream      eventStream[CarInfo]
+	entryListStream         eventStream[EntryList]
+	realtimeUpdateStream    eventStream[RealTimeUpdate]
+	realtimeCarUpdateStream eventStream[RealTimeCarUpdate]
+	connectionStateStream   eventStream[ConnectionState]
+	registrationStream      eventStream[RegistrationResult]
+	policies                [streamCount]BackpressurePolicy
+	dropped                 [streamCount]atomic.Uint64
+	pumps                   sync.WaitGroup
+	subscribersMu           sync.RWMutex
+	subscribers             map[*Subscription]struct{}
+	subscribersActive       bool
 
 	address                  string
 	displayName              string
 	connectionPassword       string
 	msRealtimeUpdateInterval int32
 	commandPassword          string
-	timeoutMs                int32
+
+	updateInterval   time.Duration
+	channelBuffer    int
+	entryListRefresh time.Duration
+	logger           *slog.Logger
+	localAddress     string
+	localAddr        *net.UDPAddr
+	remoteAddr       *net.UDPAddr
+
+	initialHandlers []Handler
+	withoutChannels bool
+	recorder        *CaptureWriter
 }
 
+// Connect registers at ACC and blocks until the registration result arrives
+// or the timeout expires. A rejected registration is returned as
+// *RegistrationError.
 func (c *AccUDPClient) Connect() (err error) {
-	var addr *net.UDPAddr
-	log.Printf("Try to connect to %s", c.address)
-	addr, err = net.ResolveUDPAddr("udp", c.address)
+	return c.connect(context.Background())
+}
+
+// connect is Connect, aborted by closing the transport when ctx is done.
+func (c *AccUDPClient) connect(ctx context.Context) (err error) {
+	if c.closed {
+		return ErrClientClosed
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	c.logger.Info("connecting", "address", c.address)
+	c.conn, err = c.dialer()
 	if err != nil {
-		log.Printf("[Error] Cannot reslove Address: %v", err)
+		c.conn = nil
+		c.logger.Error("cannot connect", "address", c.address, "error", err)
 		return err
 	}
-	c.conn, err = net.DialUDP("udp", nil, addr)
+	conn := c.conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	err = c.register()
 	if err != nil {
-		log.Printf("[Error] Cannot connect: %v", err)
-		return err
+		stop()
+		_ = c.conn.Close()
+		c.conn = nil
+		return
+	}
+	c.resetConnectionState()
+	var result RegistrationResult
+	result, err = c.awaitRegistration()
+	if !stop() {
+		// the transport was closed by the cancellation
+		err = ctx.Err()
+	}
+	if err == nil && !result.Success {
+		err = &RegistrationError{Message: result.ErrorMessage}
 	}
-	var buffer bytes.Buffer
-	err = writeRegistration(&buffer, c.displayName, c.connectionPassword, c.msRealtimeUpdateInterval, c.commandPassword)
 	if err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
 		return
 	}
-	err = c.sendBuffer(buffer)
+	c.state = c.applyRegistration(result)
+	c.start()
 	go c.listen()
 	return
 }
 
+// start prepares the delivery of events. The goroutine feeding the client
+// closes c.done when it exits.
+func (c *AccUDPClient) start() {
+	c.stopOnce = sync.Once{}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	c.err = nil
+	c.startPumps()
+}
+
+// Run connects to ACC and dispatches events until ctx is cancelled or the
+// listener fails. On cancellation the client unregisters from ACC.
+//
+// If Connect fails, e.g. because ACC is not running yet, or ctx is done before
+// the registration completes, Run returns the error and may be called again. Otherwise the event channels are closed before Run
+// returns and the client cannot be used anymore; later calls of Run and
+// Connect return ErrClientClosed.
+func (c *AccUDPClient) Run(ctx context.Context) (err error) {
+	if err = c.connect(ctx); err != nil {
+		return
+	}
+	defer c.closeChannels()
+	select {
+	case <-ctx.Done():
+		err = errors.Join(ctx.Err(), c.Disconnect())
+	case <-c.done:
+		err = c.err
+		_ = c.shutdown()
+	}
+	return
+}
+
 func (c *AccUDPClient) listen() {
+	defer close(c.done)
 	var buff [ReadBufferSize]byte
-	for c.listening {
-		err := c.conn.SetDeadline(time.Now().Add(c.timeOutDuration))
+	emit(c, &c.registrationStream, c.RegistrationEventChannel, c.registration)
+	emit(c, &c.connectionStateStream, c.ConnectionStateEventChannel, c.state)
+	for !c.stopped() {
+		c.watchdog()
+		err := c.conn.SetReadDeadline(c.readDeadline())
 		if err != nil {
-			c.ErrChannel <- err
+			if c.terminal(err) {
+				return
+			}
+			emit(c, &c.errStream, c.ErrChannel, err)
 			continue
 		}
 		n, err := c.conn.Read(buff[:])
 		if err != nil {
-			c.ErrChannel <- err
+			if c.terminal(err) {
+				return
+			}
+			if isTimeout(err) {
+				continue
+			}
+			emit(c, &c.errStream, c.ErrChannel, err)
 			continue
 		}
 		if n == ReadBufferSize {
-			log.Println("buffer not big enough")
+			c.logger.Warn("datagram exceeds read buffer", "bytes", n)
 			continue
 		}
+		c.record(CaptureInbound, buff[:n])
+		c.dispatch(buff[:n])
+	}
+}
+
+// dispatch decodes an inbound datagram and delivers it to the handlers,
+// channels and subscribers.
+func (c *AccUDPClient) dispatch(data []byte) {
+	event, err := DecodeEvent(data)
+	if err != nil {
+		c.decodeFailed(event.Type, len(data), err)
+		return
+	}
+	c.logger.Debug("received message", "connectionId", c.connectionId.Load(), "type", event.Type, "bytes", len(data))
+	switch event.Type {
+	case InboundMessageRegistrationResult:
+		result := event.Registration
+		c.logger.Info("registration result", "connectionId", result.ConnectionId, "success", result.Success, "readOnly", result.ReadOnly, "error", result.ErrorMessage)
+		emit(c, &c.registrationStream, c.RegistrationEventChannel, result)
+		c.publish(event)
+		if !result.Success {
+			emit(c, &c.errStream, c.ErrChannel, error(&RegistrationError{Message: result.ErrorMessage}))
+			return
+		}
+		c.setState(c.applyRegistration(result))
+		c.registered()
+		return
+	case InboundMessageBroadcastingEvent:
+		emit(c, &c.broadcastEventStream, c.BroadCastEventChannel, event.BroadcastEvent)
+	case InboundMessageTrackData:
+		c.recordTrack(event.TrackData)
+		emit(c, &c.trackDataStream, c.TrackDataEventChannel, event.TrackData)
+	case InboundMessageEntryListCar:
+		c.knowCar(event.EntryListCar)
+		emit(c, &c.entryListCarStream, c.EntryListCarEventChannel, event.EntryListCar)
+	case InboundMessageEntryList:
+		c.knowEntryList(event.EntryList)
+		emit(c, &c.entryListStream, c.EntryListEventChannel, event.EntryList)
+	case InboundMessageRealtimeUpdate:
+		c.lastUpdate = time.Now()
+		c.recordSession(event.RealtimeUpdate)
+		emit(c, &c.realtimeUpdateStream, c.RealtimeUpdateEventChannel, event.RealtimeUpdate)
+	case InboundMessageRealtimeCarUpdate:
+		emit(c, &c.realtimeCarUpdateStream, c.RealtimeCarUpdateEventChannel, event.RealtimeCarUpdate)
+		c.checkCar(event.RealtimeCarUpdate)
+	}
+	c.publish(event)
+}
+
+func (c *AccUDPClient) decodeFailed(inboundMessage InboundMessage, n int, err error) {
+	setDecodeMessage(inboundMessage, &err)
+	c.logger.Warn("cannot decode message", "connectionId", c.connectionId.Load(), "type", inboundMessage, "bytes", n, "error", err)
+	emit(c, &c.errStream, c.ErrChannel, err)
+}
+
+// awaitRegistration reads datagrams until ACC answers the registration.
+func (c *AccUDPClient) awaitRegistration() (result RegistrationResult, err error) {
+	var buff [ReadBufferSize]byte
+	err = c.conn.SetReadDeadline(time.Now().Add(c.timeOutDuration))
+	if err != nil {
+		return
+	}
+	for {
+		var n int
+		n, err = c.conn.Read(buff[:])
+		if err != nil {
+			if isTimeout(err) {
+				err = ErrRegistrationTimeout
+			}
+			return
+		}
+		c.record(CaptureInbound, buff[:n])
 		buffer := bytes.NewBuffer(buff[:n])
-		inboundMessage, err := readNumber[InboundMessage](buffer)
+		var inboundMessage InboundMessage
+		inboundMessage, err = readNumber[InboundMessage](buffer)
 		if err != nil {
-			c.ErrChannel <- err
-			continue
+			return
 		}
-		switch inboundMessage {
-		case InboundMessageRegistrationResult:
-			var success bool
-			var onlyRead bool
-			c.connectionId, success, onlyRead, err = readConnectionResponse(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			log.Printf("UDPConnection acknowledge ConnectionID: %v | Success: %v | onlyRead: %v", c.connectionId, success, onlyRead)
-		case InboundMessageBroadcastingEvent:
-			var event BroadCastEvent
-			event, err = readBroadcastingEvent(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			c.BroadCastEventChannel <- event
-		case InboundMessageTrackData:
-			var event TrackData
-			_, event, err = readTrackDataResponse(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			c.TrackDataEventChannel <- event
-		case InboundMessageEntryListCar:
-			var event CarInfo
-			event, err = readEntryListCarResponse(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			c.EntryListCarEventChannel <- event
-		case InboundMessageEntryList:
-			var event EntryList
-			_, event, err = readEntryListResponse(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			c.EntryListEventChannel <- event
-		case InboundMessageRealtimeUpdate:
-			var event RealTimeUpdate
-			event, err = readRealtimeUpdateResponse(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			c.RealtimeUpdateEventChannel <- event
-		case InboundMessageRealtimeCarUpdate:
-			var event RealTimeCarUpdate
-			event, err = readRealtimeCarUpdateResponse(buffer)
-			if err != nil {
-				c.ErrChannel <- err
-				continue
-			}
-			c.RealtimeCarUpdateEventChannel <- event
-		default:
-			c.ErrChannel <- errors.New("unknown inboundMessagetype")
+		if inboundMessage == InboundMessageRegistrationResult {
+			return readConnectionResponse(buffer)
 		}
 	}
 }
 
+// applyRegistration stores a successful registration and returns the
+// resulting connection state.
+func (c *AccUDPClient) applyRegistration(result RegistrationResult) ConnectionState {
+	c.registration = result
+	c.connectionId.Store(result.ConnectionId)
+	c.readOnly.Store(result.ReadOnly)
+	if result.ReadOnly {
+		return ConnectionStateReadOnly
+	}
+	return ConnectionStateRegistered
+}
+
+// IsReadOnly reports whether ACC rejected the command password. Commands that
+// control the game are refused with ErrReadOnly in that case.
+func (c *AccUDPClient) IsReadOnly() bool {
+	return c.readOnly.Load()
+}
+
+// Disconnect unregisters from ACC, closes the connection and waits for the
+// listener to exit.
 func (c *AccUDPClient) Disconnect() (err error) {
 	var buffer bytes.Buffer
-	err = writeDisconnect(&buffer, c.connectionId)
-	if err != nil {
+	err = writeDisconnect(&buffer, c.connectionId.Load())
+	if err == nil {
+		err = c.sendBuffer(buffer)
+	}
+	err = errors.Join(err, c.shutdown())
+	return
+}
+
+// shutdown stops the listener and the delivery goroutines, closes the
+// connection and waits until all of them exited.
+func (c *AccUDPClient) shutdown() (err error) {
+	if c.stop != nil {
+		c.stopOnce.Do(func() { close(c.stop) })
+	}
+	if c.conn != nil {
+		err = c.conn.Close()
+	}
+	if c.stop == nil {
+		// the listener never started
 		return
 	}
-	err = c.sendBuffer(buffer)
+	<-c.done
+	c.subscribersMu.Lock()
+	c.subscribersActive = false
+	c.subscribersMu.Unlock()
+	c.pumps.Wait()
+	return
+}
+
+func (c *AccUDPClient) stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// terminal reports whether err ends the listener and records it as the
+// terminal error unless the client is being stopped.
+func (c *AccUDPClient) terminal(err error) bool {
+	if c.stopped() {
+		return true
+	}
+	if errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		c.err = err
+		return true
+	}
+	return false
+}
+
+func (c *AccUDPClient) closeChannels() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	closeChannel(c.ErrChannel)
+	closeChannel(c.BroadCastEventChannel)
+	closeChannel(c.TrackDataEventChannel)
+	closeChannel(c.EntryListCarEventChannel)
+	closeChannel(c.EntryListEventChannel)
+	closeChannel(c.RealtimeUpdateEventChannel)
+	closeChannel(c.RealtimeCarUpdateEventChannel)
+	closeChannel(c.ConnectionStateEventChannel)
+	closeChannel(c.RegistrationEventChannel)
+	c.closeSubscriptions()
+}
+
+func closeChannel[T any](ch chan T) {
+	if ch != nil {
+		close(ch)
+	}
+}
+
+// record appends a datagram to the capture configured with WithRecorder.
+func (c *AccUDPClient) record(direction CaptureDirection, data []byte) {
+	if c.recorder == nil {
+		return
+	}
+	if direction == CaptureOutbound && len(data) > 0 && OutboundMessage(data[0]) == OutboundMessageRegisterCommandApplication {
+		data = redactRegistration(data)
+	}
+	if err := c.recorder.Record(direction, data); err != nil {
+		c.logger.Warn("cannot record datagram", "direction", direction, "bytes", len(data), "error", err)
+	}
+}
+
+// redactRegistration removes the passwords from a registration datagram, so
+// captures can be shared.
+func redactRegistration(data []byte) []byte {
+	request, err := DecodeRegistrationRequest(data)
+	if err != nil {
+		return data[:1]
+	}
+	request.ConnectionPassword = ""
+	request.CommandPassword = ""
+	redacted, err := EncodeRegistrationRequest(request)
 	if err != nil {
+		return data[:1]
+	}
+	return redacted
+}
+
+// recordTrack and recordSession complete the header of the capture, which
+// was written before the client knew the track and session.
+func (c *AccUDPClient) recordTrack(track TrackData) {
+	if c.recorder == nil {
 		return
 	}
-	err = c.conn.Close()
-	c.listening = false
-	return
+	if err := c.recorder.SetTrack(track.Id, track.Name); err != nil {
+		c.logger.Warn("cannot record track", "error", err)
+	}
+}
+
+func (c *AccUDPClient) recordSession(update RealTimeUpdate) {
+	if c.recorder == nil {
+		return
+	}
+	if err := c.recorder.SetSession(update.SessionType, update.SessionIndex); err != nil {
+		c.logger.Warn("cannot record session", "error", err)
+	}
 }
 
 func (c *AccUDPClient) sendBuffer(buffer bytes.Buffer) (err error) {
 	var n int
+	if c.conn == nil {
+		return ErrNotConnected
+	}
+	c.record(CaptureOutbound, buffer.Bytes())
 	n, err = c.conn.Write(buffer.Bytes())
+	if err != nil {
+		return
+	}
 	if n != buffer.Len() {
 		return errors.New("mismatch of length written bytes")
 	}
@@ -183,7 +550,7 @@ func (c *AccUDPClient) sendBuffer(buffer bytes.Buffer) (err error) {
 
 func (c *AccUDPClient) RequestTrackData() (err error) {
 	var buffer bytes.Buffer
-	err = writeTrackDataRequest(&buffer, c.connectionId)
+	err = writeTrackDataRequest(&buffer, c.connectionId.Load())
 	if err != nil {
 		return
 	}
@@ -193,7 +560,7 @@ func (c *AccUDPClient) RequestTrackData() (err error) {
 
 func (c *AccUDPClient) RequestEntryList() (err error) {
 	var buffer bytes.Buffer
-	err = writeEntryListRequest(&buffer, c.connectionId)
+	err = writeEntryListRequest(&buffer, c.connectionId.Load())
 	if err != nil {
 		return
 	}
@@ -202,8 +569,11 @@ func (c *AccUDPClient) RequestEntryList() (err error) {
 }
 
 func (c *AccUDPClient) RequestInstantReplay(startSessionTime float32, durationMS float32, initialFocusedCarIndex int32, initialCameraSet string, initialCamera string) (err error) {
+	if c.IsReadOnly() {
+		return ErrReadOnly
+	}
 	var buffer bytes.Buffer
-	err = writeRequestInstantReplay(&buffer, c.connectionId, startSessionTime, durationMS, initialFocusedCarIndex, initialCameraSet, initialCamera)
+	err = writeRequestInstantReplay(&buffer, c.connectionId.Load(), startSessionTime, durationMS, initialFocusedCarIndex, initialCameraSet, initialCamera)
 	if err != nil {
 		return
 	}
@@ -212,8 +582,11 @@ func (c *AccUDPClient) RequestInstantReplay(startSessionTime float32, durationMS
 }
 
 func (c *AccUDPClient) RequestHudPage(hudPage string) (err error) {
+	if c.IsReadOnly() {
+		return ErrReadOnly
+	}
 	var buffer bytes.Buffer
-	err = writeRequestHudPage(&buffer, c.connectionId, hudPage)
+	err = writeRequestHudPage(&buffer, c.connectionId.Load(), hudPage)
 	if err != nil {
 		return
 	}
@@ -222,8 +595,11 @@ func (c *AccUDPClient) RequestHudPage(hudPage string) (err error) {
 }
 
 func (c *AccUDPClient) RequestFocusedCar(carIndex goptional.Optional[uint16], cameraSet goptional.Optional[string], camera goptional.Optional[string]) (err error) {
+	if c.IsReadOnly() {
+		return ErrReadOnly
+	}
 	var buffer bytes.Buffer
-	err = writeFocusedCar(&buffer, c.connectionId, carIndex, cameraSet, camera)
+	err = writeFocusedCar(&buffer, c.connectionId.Load(), carIndex, cameraSet, camera)
 	if err != nil {
 		return
 	}